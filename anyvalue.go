@@ -2,13 +2,11 @@ package anyvalue
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
-	"errors"
 	"io"
-	"log"
 	"reflect"
 	"strconv"
-	"strings"
 
 	"github.com/vmihailenco/msgpack/v5"
 	"gopkg.in/yaml.v2"
@@ -19,10 +17,21 @@ func Version() string {
 	return "0.5.0"
 }
 
-var AVNil = &AnyValue{nil}
+// AVNil is a missing value.
+//
+// Deprecated: lookups return a fresh missing value that can be safely
+// modified, check Err() instead of comparing against AVNil.
+var AVNil = &AnyValue{miss: &LookupError{Reason: MissKeyAbsent}}
 
 type AnyValue struct {
 	data interface{}
+	// path from the value the lookup started at
+	path Path
+	// miss is set when a lookup found nothing
+	miss *LookupError
+	// opts is inherited from the value the lookup started at, nil means
+	// the package defaults
+	opts *valueOptions
 }
 
 // Implements the json.Unmarshaler interface.
@@ -35,15 +44,32 @@ func (j *AnyValue) UnmarshalJSON(p []byte) error {
 // Implements the json.Unmarshaler interface.
 func (j *AnyValue) UnmarshalMsgPack(p []byte) error {
 
-	dec := msgpack.NewDecoder(bytes.NewReader(p))
-	dec.SetCustomStructTag("json")
-	return dec.Decode(&j.data)
+	data, err := decodeMsgPack(msgpack.NewDecoder(bytes.NewReader(p)))
+	if err != nil {
+		return err
+	}
+	j.data = data
+	return nil
 }
 
 // Implements the yaml.Unmarshaler interface.
 func (j *AnyValue) UnmarshalYAML(p []byte) error {
-	dec := yaml.NewDecoder(bytes.NewBuffer(p))
-	return dec.Decode(&j.data)
+	return j.decodeYaml(bytes.NewBuffer(p), newDecodeOptions(nil))
+}
+
+func (j *AnyValue) decodeYaml(r io.Reader, o *decodeOptions) error {
+	data, err := decodeYamlData(r)
+	if err != nil {
+		return err
+	}
+	o.binaryStrings = true
+	data, err = normalize(data, o)
+	if err != nil {
+		return err
+	}
+	j.data = data
+	j.miss = nil
+	return nil
 }
 
 // NewFromReader returns a *AnyValue by decoding from an io.Reader
@@ -58,88 +84,43 @@ func NewFromJsonReader(r io.Reader) (*AnyValue, error) {
 // NewFromReader returns a *AnyValue by decoding from an io.Reader
 func NewFromMsgPackReader(r io.Reader) (*AnyValue, error) {
 	j := new(AnyValue)
-	dec := msgpack.NewDecoder(r)
-	dec.SetCustomStructTag("json")
-	err := dec.Decode(&j.data)
+	data, err := decodeMsgPack(msgpack.NewDecoder(r))
+	j.data = data
 	return j, err
 }
 
-// NewFromReader returns a *AnyValue by decoding from an io.Reader
-func NewFromYamlReader(r io.Reader) (*AnyValue, error) {
+// NewFromReader returns a *AnyValue by decoding from an io.Reader,
+// maps with non-string keys are converted unless StrictKeys is given
+func NewFromYamlReader(r io.Reader, opts ...DecodeOption) (*AnyValue, error) {
 	j := new(AnyValue)
-	dec := yaml.NewDecoder(r)
-	err := dec.Decode(&j.data)
+	err := j.decodeYaml(r, newDecodeOptions(opts))
 	return j, err
 }
 
 // Float64 coerces into a float64
 func (j *AnyValue) Float64() (float64, error) {
-	switch j.data.(type) {
-	case json.Number:
-		return j.data.(json.Number).Float64()
-	case float32, float64:
-		return reflect.ValueOf(j.data).Float(), nil
-	case int, int8, int16, int32, int64:
-		return float64(reflect.ValueOf(j.data).Int()), nil
-	case uint, uint8, uint16, uint32, uint64:
-		return float64(reflect.ValueOf(j.data).Uint()), nil
-	}
-	return 0, errors.New("invalid value type")
+	return j.float(64, "float64")
 }
 
 // Int coerces into an int
 func (j *AnyValue) Int() (int, error) {
-	switch j.data.(type) {
-	case json.Number:
-		i, err := j.data.(json.Number).Int64()
-		return int(i), err
-	case float32, float64:
-		return int(reflect.ValueOf(j.data).Float()), nil
-	case int, int8, int16, int32, int64:
-		return int(reflect.ValueOf(j.data).Int()), nil
-	case uint, uint8, uint16, uint32, uint64:
-		return int(reflect.ValueOf(j.data).Uint()), nil
-	}
-	return 0, errors.New("invalid value type")
+	i, err := j.signed(strconv.IntSize, "int")
+	return int(i), err
 }
 
+// IsNumber reports whether the value is a number of any Go type
 func (j *AnyValue) IsNumber() bool {
-
-	_, err := j.Int()
-	if err != nil {
-		return false
-	}
-	return true
+	return j.Kind() == Number
 }
 
 // Int64 coerces into an int64
 func (j *AnyValue) Int64() (int64, error) {
-	switch j.data.(type) {
-	case json.Number:
-		return j.data.(json.Number).Int64()
-	case float32, float64:
-		return int64(reflect.ValueOf(j.data).Float()), nil
-	case int, int8, int16, int32, int64:
-		return reflect.ValueOf(j.data).Int(), nil
-	case uint, uint8, uint16, uint32, uint64:
-		return int64(reflect.ValueOf(j.data).Uint()), nil
-	}
-	return 0, errors.New("invalid value type")
+	return j.signed(64, "int64")
 }
 
 // Uint64 coerces into an uint64
 func (j *AnyValue) Uint64() (uint64, error) {
-	switch j.data.(type) {
-	case json.Number:
-		return strconv.ParseUint(j.data.(json.Number).String(), 10, 64)
-	case float32, float64:
-		return uint64(reflect.ValueOf(j.data).Float()), nil
-	case int, int8, int16, int32, int64:
-		return uint64(reflect.ValueOf(j.data).Int()), nil
-	case uint, uint8, uint16, uint32, uint64:
-		return reflect.ValueOf(j.data).Uint(), nil
-	}
-	return 0, errors.New("invalid value type")
+	return j.unsigned(64, "uint64")
 }
 
 // NewFromJson returns a pointer to a new `AnyValue` object
@@ -165,10 +146,13 @@ func NewFromMsgPack(body []byte) (*AnyValue, error) {
 }
 
 // NewFromYaml returns a pointer to a new `AnyValue` object
-// after unmarshaling `body` bytes
-func NewFromYaml(body []byte) (*AnyValue, error) {
+// after unmarshaling `body` bytes, maps with non-string keys are
+// converted unless StrictKeys is given. The keys are converted from the
+// value yaml.v2 resolved, so YAML 1.1 bool words such as `y` or `off`
+// become "true" and "false", see StrictKeys.
+func NewFromYaml(body []byte, opts ...DecodeOption) (*AnyValue, error) {
 	j := new(AnyValue)
-	err := j.UnmarshalYAML(body)
+	err := j.decodeYaml(bytes.NewBuffer(body), newDecodeOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -182,8 +166,10 @@ func New() *AnyValue {
 	}
 }
 
-// New returns a pointer to a new, empty `AnyValue` object
+// NewFromInf returns a pointer to a new `AnyValue` object wrapping `data`,
+// maps with non-string keys are converted to map[string]interface{}
 func NewFromInf(data interface{}) *AnyValue {
+	data, _ = normalize(data, newDecodeOptions(nil))
 	return &AnyValue{
 		data: data,
 	}
@@ -206,20 +192,32 @@ func (j *AnyValue) EncodeMsgPack() ([]byte, error) {
 
 // EncodePretty returns its marshaled data as `[]byte` with indentation
 func (j *AnyValue) EncodeJsonPretty() ([]byte, error) {
-	return json.MarshalIndent(&j.data, "", "  ")
+	data, err := newEncoder(formatJSON).encodable(j.data)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(&data, "", "  ")
 }
 
 // Implements the json.Marshaler interface.
 func (j *AnyValue) MarshalJSON() ([]byte, error) {
-	return json.Marshal(&j.data)
+	data, err := newEncoder(formatJSON).encodable(j.data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&data)
 }
 
 // Implements the msgpack.Marshaler interface.
 func (j *AnyValue) MarshalMsgPack() ([]byte, error) {
+	data, err := newEncoder(formatMsgPack).encodable(j.data)
+	if err != nil {
+		return nil, err
+	}
 	var buf bytes.Buffer
 	enc := msgpack.NewEncoder(&buf)
 	enc.SetCustomStructTag("json")
-	err := enc.Encode(&j.data)
+	err = enc.Encode(&data)
 	return buf.Bytes(), err
 }
 
@@ -230,77 +228,163 @@ func (j *AnyValue) EncodeYaml() ([]byte, error) {
 
 // Implements the yaml.Marshaler interface.
 func (j *AnyValue) MarshalYAML() ([]byte, error) {
+	e := newEncoder(formatYAML)
+	data, err := e.encodable(j.data)
+	if err != nil {
+		return nil, err
+	}
+	out, err := yaml.Marshal(&data)
+	if err != nil {
+		return nil, err
+	}
+	return e.restore(out), nil
+}
 
-	return yaml.Marshal(&j.data)
+// Set writes `val` at `path`, creating missing maps and arrays on the way,
+// see Path for the path grammar. Arrays are extended when the index is past
+// their end, by at most 65536 slots, and `servers[]` appends a new element.
+// A malformed or unassignable path leaves the value untouched, use TrySet to
+// get the error.
+// A time.Duration is stored as its string, such as "30s", which is how every
+// encoder writes it and what Duration reads.
+//
+//	js.Set("servers[2].port", 80, anyvalue.WithFill(map[string]interface{}{}))
+//	js.Set("servers[].addr", "10.0.0.1")
+func (j *AnyValue) Set(path string, val interface{}, opts ...SetOption) *AnyValue {
+	j.TrySet(path, val, opts...)
+	return j
 }
 
-func (j *AnyValue) Set(path string, val interface{}) *AnyValue {
-	branch := strings.Split(path, ".")
-	return j.SetPath(branch, val)
+// TrySet is like Set but reports malformed paths and paths that cannot be
+// assigned, such as a key on an existing array
+func (j *AnyValue) TrySet(path string, val interface{}, opts ...SetOption) error {
+	p, err := ParsePath(path)
+	if err != nil {
+		return err
+	}
+	return j.setPath(p, val, newSetOptions(opts))
 }
 
 // SetPath modifies `AnyValue`, recursively checking/creating map keys for the supplied path,
 // and then finally writing in the value
 func (j *AnyValue) SetPath(branch []string, val interface{}) *AnyValue {
-	if len(branch) == 0 {
-		j.data = val
-		return j
+	j.setPath(KeyPath(branch...), val, newSetOptions(nil))
+	return j
+}
+
+func (j *AnyValue) setPath(p Path, val interface{}, o *setOptions) error {
+	val, _ = normalize(val, newDecodeOptions(nil))
+	data, err := p.assign(j.data, val, o)
+	if err != nil {
+		// assign does not know the path it was given
+		if pe, ok := err.(*PathError); ok {
+			pe.Path = j.fullPath(p).String()
+		}
+		return err
 	}
+	j.data = data
+	j.miss = nil
+	return nil
+}
 
-	// in order to insert our branch, we need map[string]interface{}
-	if _, ok := (j.data).(map[string]interface{}); !ok {
-		// have to replace with something suitable
-		j.data = make(map[string]interface{})
+// Del deletes the value at `path`, a map key or an array element addressed
+// like Get does, and reports whether there was one. Deleting an array element
+// shifts the following ones down, deleting the empty path sets the value to
+// null.
+func (j *AnyValue) Del(path string, opts ...DelOption) bool {
+	p, err := ParsePath(path)
+	if err != nil {
+		return false
 	}
-	curr := j.data.(map[string]interface{})
+	return j.del(p, 0, newDelOptions(opts))
+}
 
-	for i := 0; i < len(branch)-1; i++ {
-		b := branch[i]
-		// key exists?
-		if _, ok := curr[b]; !ok {
-			n := make(map[string]interface{})
-			curr[b] = n
-			curr = n
-			continue
+// del deletes the value at `p`, pruning leaves the first `keep` segments of
+// `p` in place
+func (j *AnyValue) del(p Path, keep int, o *delOptions) bool {
+	if j.delPath(p) != nil {
+		return false
+	}
+	if o.prune {
+		for n := len(p) - 1; n > keep; n-- {
+			parent := j.getPath(p[:n])
+			if !(parent.IsMap() || parent.IsArray()) || parent.Len() > 0 {
+				break
+			}
+			j.delPath(p[:n])
 		}
+	}
+	return true
+}
 
-		// make sure the value is the right sort of thing
-		if _, ok := curr[b].(map[string]interface{}); !ok {
-			// have to replace with something suitable
-			n := make(map[string]interface{})
-			curr[b] = n
-		}
+// delPath deletes the value addressed by `p` and fails with a *LookupError
+// when there is none, deleting the empty path sets the value to null
+func (j *AnyValue) delPath(p Path) error {
+	if len(p) == 0 {
+		j.data = nil
+		return nil
+	}
+	data, n, reason := p.remove(j.data)
+	if reason != MissNone {
+		return j.lookupError(p, n, reason)
+	}
+	j.data = data
+	return nil
+}
 
-		curr = curr[b].(map[string]interface{})
+// getPath returns a pointer to a new `AnyValue` object for the value
+// addressed by `p`, or a detached missing value recording why it was not found
+func (j *AnyValue) getPath(p Path) *AnyValue {
+	full := j.fullPath(p)
+	if j.miss != nil {
+		miss := *j.miss
+		miss.Path = full.String()
+		return &AnyValue{path: full, miss: &miss, opts: j.opts}
 	}
 
-	// add remaining k/v
-	curr[branch[len(branch)-1]] = val
-	return j
+	val, n, reason := p.lookup(j.data)
+	if reason != MissNone {
+		return &AnyValue{path: full, miss: j.lookupError(p, n, reason), opts: j.opts}
+	}
+	return &AnyValue{data: val, path: full, opts: j.opts}
 }
 
-// Del modifies `AnyValue` map by deleting `key` if it is present.
-func (j *AnyValue) Del(key string) {
-	m, err := j.Map()
-	if err != nil {
-		return
+// fullPath returns `p` prefixed with the path of j
+func (j *AnyValue) fullPath(p Path) Path {
+	return append(append(Path{}, j.path...), p...)
+}
+
+// lookupError reports that the lookup of `p` stopped after `n` segments
+func (j *AnyValue) lookupError(p Path, n int, reason MissReason) *LookupError {
+	full := j.fullPath(p)
+	return &LookupError{
+		Path:   full.String(),
+		At:     full[:len(j.path)+n+1].String(),
+		Reason: reason,
 	}
-	delete(m, key)
 }
 
-// Get returns a pointer to a new `AnyValue` object
-// for `key` in its `map` representation
-//
-// useful for chaining operations (to traverse a nested JSON):
-//    js.Get("top_level").Get("dict").Get("value").Int()
-func (j *AnyValue) getValue(key string) *AnyValue {
-	m, err := j.Map()
-	if err == nil {
-		if val, ok := m[key]; ok {
-			return &AnyValue{val}
-		}
+// missingPath returns a missing value for a path that could not be parsed
+func (j *AnyValue) missingPath(path string, err error) *AnyValue {
+	return &AnyValue{path: j.path, opts: j.opts, miss: &LookupError{
+		Path:   path,
+		Reason: MissInvalidPath,
+		Err:    err,
+	}}
+}
+
+// Err returns a *LookupError telling why the value is missing, or nil when
+// the lookup found a value
+func (j *AnyValue) Err() error {
+	if j.miss == nil {
+		return nil
 	}
-	return AVNil
+	return j.miss
+}
+
+// Path returns the path the value was looked up at
+func (j *AnyValue) Path() string {
+	return j.path.String()
 }
 
 // GetPath searches for the item as specified by the branch
@@ -308,27 +392,23 @@ func (j *AnyValue) getValue(key string) *AnyValue {
 //
 //   js.GetPath("top_level", "dict")
 func (j *AnyValue) GetPath(branch ...string) *AnyValue {
-	jin := j
-	for _, p := range branch {
-		jin = jin.getValue(p)
-	}
-	return jin
+	return j.getPath(KeyPath(branch...))
 }
 
-// GetValue searches for the item as specified by the branch
+// Get searches for the item as specified by the path
 // without the need to deep dive using Get()'s.
 //
-//   js.GetValue("top_level.dict")
+//   js.Get("top_level.dict")
+//   js.Get("servers[0].addr")
 func (j *AnyValue) Get(path string) *AnyValue {
-	branch := strings.Split(path, ".")
-	jin := j
-	for _, p := range branch {
-		jin = jin.getValue(p)
+	p, err := ParsePath(path)
+	if err != nil {
+		return j.missingPath(path, err)
 	}
-	return jin
+	return j.getPath(p)
 }
 
-// CheckGet returns a pointer to a new `AnyValue` object and
+// Exist returns a pointer to a new `AnyValue` object and
 // a `bool` identifying success or failure
 //
 // useful for chained operations when success is important:
@@ -336,33 +416,14 @@ func (j *AnyValue) Get(path string) *AnyValue {
 //        log.Println(data)
 //    }
 func (j *AnyValue) Exist(path string) (*AnyValue, bool) {
-	branch := strings.Split(path, ".")
-	jin := j
-	for _, p := range branch {
-
-		jin = jin.getValue(p)
-	}
-
-	if jin != AVNil {
-		return jin, true
-	} else {
-		return jin, false
-	}
+	jin := j.Get(path)
+	return jin, jin.miss == nil
 }
 
+// Has reports whether `path` addresses an existing value
 func (j *AnyValue) Has(path string) bool {
-	branch := strings.Split(path, ".")
-	jin := j
-	for _, p := range branch {
-
-		jin = jin.getValue(p)
-	}
-
-	if jin != AVNil {
-		return true
-	} else {
-		return false
-	}
+	_, ok := j.Exist(path)
+	return ok
 }
 
 // GetIndex returns a pointer to a new `AnyValue` object
@@ -372,38 +433,20 @@ func (j *AnyValue) Has(path string) bool {
 // a json array instead of a json object:
 //    js.Get("top_level").Get("array").GetIndex(1).Get("key").Int()
 func (j *AnyValue) GetIndex(index int) *AnyValue {
-	a, err := j.Array()
-	if err == nil {
-		if len(a) > index {
-			return &AnyValue{a[index]}
-		}
-	}
-	return AVNil
+	return j.getPath(Path{{Kind: SegmentIndex, Index: index}})
 }
 
 // Map type asserts to `map`
 func (j *AnyValue) Map() (map[string]interface{}, error) {
 	if m, ok := (j.data).(map[string]interface{}); ok {
 		return m, nil
-	} else if m, ok := (j.data).(map[interface{}]interface{}); ok {
-		var mn map[string]interface{}
-		mn = make(map[string]interface{})
-		for k, v := range m {
-			kn, ok := k.(string)
-			if ok {
-				mn[kn] = v
-			}
-		}
-		return mn, nil
 	}
-	return nil, errors.New("type assertion to map[string]interface{} failed")
+	return nil, j.mismatch(Object)
 }
 
 func (j *AnyValue) IsMap() bool {
 	if _, ok := (j.data).(map[string]interface{}); ok {
 		return true
-	} else if _, ok := (j.data).(map[interface{}]interface{}); ok {
-		return true
 	}
 	return false
 }
@@ -413,7 +456,7 @@ func (j *AnyValue) Array() ([]interface{}, error) {
 	if a, ok := (j.data).([]interface{}); ok {
 		return a, nil
 	}
-	return nil, errors.New("type assertion to []interface{} failed")
+	return nil, j.mismatch(Array)
 }
 
 func (j *AnyValue) IsArray() bool {
@@ -423,12 +466,15 @@ func (j *AnyValue) IsArray() bool {
 	return false
 }
 
-// Bool type asserts to `bool`
+// Bool type asserts to `bool`, or reads a string under WithConversion
 func (j *AnyValue) Bool() (bool, error) {
 	if s, ok := (j.data).(bool); ok {
 		return s, nil
 	}
-	return false, errors.New("type assertion to bool failed")
+	if s, ok := (j.data).(string); ok && j.options().conversion != nil {
+		return j.parseBool(s)
+	}
+	return false, j.mismatch(Bool)
 }
 
 func (j *AnyValue) IsBool() bool {
@@ -438,12 +484,18 @@ func (j *AnyValue) IsBool() bool {
 	return false
 }
 
-// String type asserts to `string`
+// String type asserts to `string`, or renders a number or a bool under
+// WithConversion
 func (j *AnyValue) Str() (string, error) {
 	if s, ok := (j.data).(string); ok {
 		return s, nil
 	}
-	return "", errors.New("type assertion to string failed")
+	if j.options().conversion != nil {
+		if s, ok := formatScalar(j.data); ok {
+			return s, nil
+		}
+	}
+	return "", j.mismatch(String)
 }
 
 func (j *AnyValue) IsStr() bool {
@@ -453,16 +505,49 @@ func (j *AnyValue) IsStr() bool {
 	return false
 }
 
-// Bytes type asserts to `[]byte`
+// Bytes returns binary data: a []byte, such as a msgpack bin or a YAML
+// !!binary value, as is, or the bytes of a string.
 func (j *AnyValue) Bytes() ([]byte, error) {
-	if s, ok := (j.data).(string); ok {
-		return []byte(s), nil
+	switch b := (j.data).(type) {
+	case []byte:
+		return b, nil
+	case string:
+		return []byte(b), nil
+	}
+	return nil, j.mismatch(Binary)
+}
+
+// Base64Bytes is like Bytes but decodes strings as base64, padded or not,
+// the form the JSON encoder writes []byte in
+func (j *AnyValue) Base64Bytes() ([]byte, error) {
+	switch b := (j.data).(type) {
+	case []byte:
+		return b, nil
+	case string:
+		data, err := decodeBase64(b)
+		if err != nil {
+			return nil, j.invalid(Binary, "invalid base64 data")
+		}
+		return data, nil
+	}
+	return nil, j.mismatch(Binary)
+}
+
+// decodeBase64 decodes standard base64, padded or not
+func decodeBase64(s string) ([]byte, error) {
+	if data, err := base64.StdEncoding.DecodeString(s); err == nil {
+		return data, nil
 	}
-	return nil, errors.New("type assertion to []byte failed")
+	return base64.RawStdEncoding.DecodeString(s)
 }
 
-// StrArr type asserts to an `array` of `string`
+// StrArr type asserts to an `array` of `string`. Under Strict coercion a
+// non-string element is an error citing its index, under Lenient nulls are
+// read as "" and other elements are dropped.
 func (j *AnyValue) StrArr() ([]string, error) {
+	if j.options().coercion == Strict {
+		return j.strictStrArr()
+	}
 	arr, err := j.Array()
 	if err != nil {
 		return nil, err
@@ -482,8 +567,13 @@ func (j *AnyValue) StrArr() ([]string, error) {
 	return retArr, nil
 }
 
-// IntArr type asserts to an `array` of `int`
+// Int64Arr coerces into an `array` of `int64`. Under Strict coercion an
+// element that is not a number or does not fit is an error citing its
+// index, under Lenient nulls and non-numbers are read as 0.
 func (j *AnyValue) Int64Arr() ([]int64, error) {
+	if j.options().coercion == Strict {
+		return j.strictInt64Arr()
+	}
 	arr, err := j.Array()
 	if err != nil {
 		return nil, err
@@ -510,8 +600,11 @@ func (j *AnyValue) Int64Arr() ([]int64, error) {
 	return retArr, nil
 }
 
-// IntArr type asserts to an `array` of `int`
+// UInt64Arr coerces into an `array` of `uint64`, see Int64Arr
 func (j *AnyValue) UInt64Arr() ([]uint64, error) {
+	if j.options().coercion == Strict {
+		return j.strictUint64Arr()
+	}
 	arr, err := j.Array()
 	if err != nil {
 		return nil, err
@@ -538,8 +631,11 @@ func (j *AnyValue) UInt64Arr() ([]uint64, error) {
 	return retArr, nil
 }
 
-// IntArr type asserts to an `array` of `int`
+// Float64Arr coerces into an `array` of `float64`, see Int64Arr
 func (j *AnyValue) Float64Arr() ([]float64, error) {
+	if j.options().coercion == Strict {
+		return j.strictFloat64Arr()
+	}
 	arr, err := j.Array()
 	if err != nil {
 		return nil, err
@@ -573,22 +669,7 @@ func (j *AnyValue) Float64Arr() ([]float64, error) {
 //			fmt.Println(i, v)
 //		}
 func (j *AnyValue) AsArray(args ...[]interface{}) []interface{} {
-	var def []interface{}
-
-	switch len(args) {
-	case 0:
-	case 1:
-		def = args[0]
-	default:
-		log.Panicf("AsArray() received too many arguments %d", len(args))
-	}
-
-	a, err := j.Array()
-	if err == nil {
-		return a
-	}
-
-	return def
+	return orDefault(j.Array, args)
 }
 
 // AsMap guarantees the return of a `map[string]interface{}` (with optional default)
@@ -598,22 +679,7 @@ func (j *AnyValue) AsArray(args ...[]interface{}) []interface{} {
 //			fmt.Println(k, v)
 //		}
 func (j *AnyValue) AsMap(args ...map[string]interface{}) map[string]interface{} {
-	var def map[string]interface{}
-
-	switch len(args) {
-	case 0:
-	case 1:
-		def = args[0]
-	default:
-		log.Panicf("AsMap() received too many arguments %d", len(args))
-	}
-
-	a, err := j.Map()
-	if err == nil {
-		return a
-	}
-
-	return def
+	return orDefault(j.Map, args)
 }
 
 // AsStr guarantees the return of a `string` (with optional default)
@@ -621,22 +687,7 @@ func (j *AnyValue) AsMap(args ...map[string]interface{}) map[string]interface{}
 // useful when you explicitly want a `string` in a single value return context:
 //     myFunc(js.Get("param1").AsStr(), js.Get("optional_param").AsStr("Asmy_ault"))
 func (j *AnyValue) AsStr(args ...string) string {
-	var def string
-
-	switch len(args) {
-	case 0:
-	case 1:
-		def = args[0]
-	default:
-		log.Panicf("AsString() received too many arguments %d", len(args))
-	}
-
-	s, err := j.Str()
-	if err == nil {
-		return s
-	}
-
-	return def
+	return orDefault(j.Str, args)
 }
 
 // AsStrArr guarantees the return of a `[]string` (with optional default)
@@ -646,79 +697,19 @@ func (j *AnyValue) AsStr(args ...string) string {
 //			fmt.Println(i, s)
 //		}
 func (j *AnyValue) AsStrArr(args ...[]string) []string {
-	var def []string
-
-	switch len(args) {
-	case 0:
-	case 1:
-		def = args[0]
-	default:
-		log.Panicf("AsStrArr() received too many arguments %d", len(args))
-	}
-
-	a, err := j.StrArr()
-	if err == nil {
-		return a
-	}
-
-	return def
+	return orDefault(j.StrArr, args)
 }
 
 func (j *AnyValue) AsInt64Arr(args ...[]int64) []int64 {
-	var def []int64
-
-	switch len(args) {
-	case 0:
-	case 1:
-		def = args[0]
-	default:
-		log.Panicf("AsStrArr() received too many arguments %d", len(args))
-	}
-
-	a, err := j.Int64Arr()
-	if err == nil {
-		return a
-	}
-
-	return def
+	return orDefault(j.Int64Arr, args)
 }
 
 func (j *AnyValue) AsUInt64Arr(args ...[]uint64) []uint64 {
-	var def []uint64
-
-	switch len(args) {
-	case 0:
-	case 1:
-		def = args[0]
-	default:
-		log.Panicf("AsStrArr() received too many arguments %d", len(args))
-	}
-
-	a, err := j.UInt64Arr()
-	if err == nil {
-		return a
-	}
-
-	return def
+	return orDefault(j.UInt64Arr, args)
 }
 
 func (j *AnyValue) AsFloat64Arr(args ...[]float64) []float64 {
-	var def []float64
-
-	switch len(args) {
-	case 0:
-	case 1:
-		def = args[0]
-	default:
-		log.Panicf("AsStrArr() received too many arguments %d", len(args))
-	}
-
-	a, err := j.Float64Arr()
-	if err == nil {
-		return a
-	}
-
-	return def
+	return orDefault(j.Float64Arr, args)
 }
 
 // AsInt guarantees the return of an `int` (with optional default)
@@ -726,22 +717,7 @@ func (j *AnyValue) AsFloat64Arr(args ...[]float64) []float64 {
 // useful when you explicitly want an `int` in a single value return context:
 //     myFunc(js.Get("param1").AsInt(), js.Get("optional_param").AsInt(5150))
 func (j *AnyValue) AsInt(args ...int) int {
-	var def int
-
-	switch len(args) {
-	case 0:
-	case 1:
-		def = args[0]
-	default:
-		log.Panicf("AsInt() received too many arguments %d", len(args))
-	}
-
-	i, err := j.Int()
-	if err == nil {
-		return i
-	}
-
-	return def
+	return orDefault(j.Int, args)
 }
 
 // AsFloat64 guarantees the return of a `float64` (with optional default)
@@ -749,22 +725,7 @@ func (j *AnyValue) AsInt(args ...int) int {
 // useful when you explicitly want a `float64` in a single value return context:
 //     myFunc(js.Get("param1").AsFloat64(), js.Get("optional_param").AsFloat64(5.150))
 func (j *AnyValue) AsFloat64(args ...float64) float64 {
-	var def float64
-
-	switch len(args) {
-	case 0:
-	case 1:
-		def = args[0]
-	default:
-		log.Panicf("AsFloat64() received too many arguments %d", len(args))
-	}
-
-	f, err := j.Float64()
-	if err == nil {
-		return f
-	}
-
-	return def
+	return orDefault(j.Float64, args)
 }
 
 // AsBool guarantees the return of a `bool` (with optional default)
@@ -772,22 +733,7 @@ func (j *AnyValue) AsFloat64(args ...float64) float64 {
 // useful when you explicitly want a `bool` in a single value return context:
 //     myFunc(js.Get("param1").AsBool(), js.Get("optional_param").AsBool(true))
 func (j *AnyValue) AsBool(args ...bool) bool {
-	var def bool
-
-	switch len(args) {
-	case 0:
-	case 1:
-		def = args[0]
-	default:
-		log.Panicf("AsBool() received too many arguments %d", len(args))
-	}
-
-	b, err := j.Bool()
-	if err == nil {
-		return b
-	}
-
-	return def
+	return orDefault(j.Bool, args)
 }
 
 // AsInt64 guarantees the return of an `int64` (with optional default)
@@ -795,22 +741,7 @@ func (j *AnyValue) AsBool(args ...bool) bool {
 // useful when you explicitly want an `int64` in a single value return context:
 //     myFunc(js.Get("param1").AsInt64(), js.Get("optional_param").AsInt64(5150))
 func (j *AnyValue) AsInt64(args ...int64) int64 {
-	var def int64
-
-	switch len(args) {
-	case 0:
-	case 1:
-		def = args[0]
-	default:
-		log.Panicf("AsInt64() received too many arguments %d", len(args))
-	}
-
-	i, err := j.Int64()
-	if err == nil {
-		return i
-	}
-
-	return def
+	return orDefault(j.Int64, args)
 }
 
 // AsUInt64 guarantees the return of an `uint64` (with optional default)
@@ -818,20 +749,5 @@ func (j *AnyValue) AsInt64(args ...int64) int64 {
 // useful when you explicitly want an `uint64` in a single value return context:
 //     myFunc(js.Get("param1").AsUint64(), js.Get("optional_param").AsUint64(5150))
 func (j *AnyValue) AsUint64(args ...uint64) uint64 {
-	var def uint64
-
-	switch len(args) {
-	case 0:
-	case 1:
-		def = args[0]
-	default:
-		log.Panicf("AsUint64() received too many arguments %d", len(args))
-	}
-
-	i, err := j.Uint64()
-	if err == nil {
-		return i
-	}
-
-	return def
+	return orDefault(j.Uint64, args)
 }
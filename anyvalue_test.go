@@ -115,3 +115,52 @@ func TestMsgPack(t *testing.T) {
 	out, err = av.EncodeJson()
 	t.Logf("out=%v", string(out))
 }
+
+func TestDelPath(t *testing.T) {
+	doc, err := NewFromYaml([]byte(`
+a:
+  b:
+    c: 1
+  keep: x
+servers:
+  - {addr: a, tags: [t]}
+  - {addr: b}
+dotted.key: 1
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !doc.Del("servers[0].tags[0]", WithPruneEmpty()) {
+		t.Fatal("expected an element to be removed")
+	}
+	if doc.Has("servers[0].tags") || doc.Get("servers[0].addr").AsStr() != "a" {
+		t.Fatal("empty array not pruned")
+	}
+	if !doc.Del("servers[-1]") || doc.Get("servers").Len() != 1 {
+		t.Fatal("array element not removed")
+	}
+	if !doc.Del("a.b.c", WithPruneEmpty()) || doc.Has("a.b") || !doc.Has("a.keep") {
+		t.Fatal("expected a.b pruned and a kept")
+	}
+	if !doc.Del(`["dotted.key"]`) || doc.Has(`["dotted.key"]`) {
+		t.Fatal("quoted key not removed")
+	}
+	if doc.Del("a.nope") || doc.Del("servers[5]") || doc.Del("a[") {
+		t.Fatal("Del reported removing a missing value")
+	}
+
+	config, err := LoadConfigYaml("./config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gin := config.Sub("gin")
+	gin.Del("mode", WithPruneEmpty())
+	gin.Del("log", WithPruneEmpty())
+	if !config.Has("gin") || config.Get("gin").Len() != 0 {
+		t.Fatal("pruning went past the cursor section")
+	}
+	if !gin.Del("") || config.Has("gin") {
+		t.Fatal("cursor section not removed")
+	}
+}
@@ -0,0 +1,275 @@
+package anyvalue
+
+import (
+	"fmt"
+	"time"
+)
+
+// Elems returns the elements of an array as values that carry their path,
+// so their accessors report errors such as "servers[2].port: ..."
+func (j *AnyValue) Elems() ([]*AnyValue, error) {
+	arr, err := j.Array()
+	if err != nil {
+		return nil, err
+	}
+	elems := make([]*AnyValue, len(arr))
+	for i := range arr {
+		elems[i] = j.GetIndex(i)
+	}
+	return elems, nil
+}
+
+func (j *AnyValue) strictStrArr() ([]string, error) {
+	elems, err := j.Elems()
+	if err != nil {
+		return nil, err
+	}
+	a := make([]string, len(elems))
+	for i, e := range elems {
+		if a[i], err = e.Str(); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+func (j *AnyValue) strictInt64Arr() ([]int64, error) {
+	elems, err := j.Elems()
+	if err != nil {
+		return nil, err
+	}
+	a := make([]int64, len(elems))
+	for i, e := range elems {
+		if a[i], err = e.Int64(); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+func (j *AnyValue) strictUint64Arr() ([]uint64, error) {
+	elems, err := j.Elems()
+	if err != nil {
+		return nil, err
+	}
+	a := make([]uint64, len(elems))
+	for i, e := range elems {
+		if a[i], err = e.Uint64(); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+func (j *AnyValue) strictFloat64Arr() ([]float64, error) {
+	elems, err := j.Elems()
+	if err != nil {
+		return nil, err
+	}
+	a := make([]float64, len(elems))
+	for i, e := range elems {
+		if a[i], err = e.Float64(); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// BoolArr type asserts to an `array` of `bool`, an element that is not a
+// bool is an error citing its index
+func (j *AnyValue) BoolArr() ([]bool, error) {
+	elems, err := j.Elems()
+	if err != nil {
+		return nil, err
+	}
+	a := make([]bool, len(elems))
+	for i, e := range elems {
+		if a[i], err = e.Bool(); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// MapArr type asserts to an `array` of `map`, an element that is not an
+// object is an error citing its index
+func (j *AnyValue) MapArr() ([]map[string]interface{}, error) {
+	elems, err := j.Elems()
+	if err != nil {
+		return nil, err
+	}
+	a := make([]map[string]interface{}, len(elems))
+	for i, e := range elems {
+		if a[i], err = e.Map(); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// DurationArr reads an `array` of `time.Duration` the way Duration does, an
+// invalid element is an error citing its index
+func (j *AnyValue) DurationArr() ([]time.Duration, error) {
+	elems, err := j.Elems()
+	if err != nil {
+		return nil, err
+	}
+	a := make([]time.Duration, len(elems))
+	for i, e := range elems {
+		if a[i], err = e.Duration(); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// AsBoolArr guarantees the return of a `[]bool` (with optional default)
+func (j *AnyValue) AsBoolArr(args ...[]bool) []bool {
+	return orDefault(j.BoolArr, args)
+}
+
+// AsMapArr guarantees the return of a `[]map[string]interface{}` (with
+// optional default)
+func (j *AnyValue) AsMapArr(args ...[]map[string]interface{}) []map[string]interface{} {
+	return orDefault(j.MapArr, args)
+}
+
+// AsDurationArr guarantees the return of a `[]time.Duration` (with optional
+// default)
+func (j *AnyValue) AsDurationArr(args ...[]time.Duration) []time.Duration {
+	return orDefault(j.DurationArr, args)
+}
+
+// Len returns the number of elements of an array or the number of keys of a
+// map, and 0 for any other value
+func (j *AnyValue) Len() int {
+	switch c := j.data.(type) {
+	case []interface{}:
+		return len(c)
+	case map[string]interface{}:
+		return len(c)
+	}
+	return 0
+}
+
+// Slice returns a copy of the elements `from` up to but not including `to`
+// of an array, negative indexes count from the end as they do in paths.
+// Modifying the copy does not modify j.
+func (j *AnyValue) Slice(from, to int) (*AnyValue, error) {
+	arr, err := j.Array()
+	if err != nil {
+		return nil, err
+	}
+	lo, hi := from, to
+	if lo < 0 {
+		lo += len(arr)
+	}
+	if hi < 0 {
+		hi += len(arr)
+	}
+	if lo < 0 || hi > len(arr) || lo > hi {
+		return nil, &PathError{
+			Path:     j.path.String(),
+			Expected: Array,
+			Actual:   Array,
+			Msg:      fmt.Sprintf("slice [%d:%d] out of range for length %d", from, to, len(arr)),
+			Err:      ErrNotFound,
+		}
+	}
+	return &AnyValue{data: deepCopy(arr[lo:hi]), opts: j.opts}, nil
+}
+
+// Append adds `vals` to the end of the array at `path`, creating the array
+// like Set does when there is no value at `path`
+func (j *AnyValue) Append(path string, vals ...interface{}) error {
+	return j.modifyArray(path, true, func(arr []interface{}) ([]interface{}, error) {
+		a := make([]interface{}, 0, len(arr)+len(vals))
+		return append(append(a, arr...), vals...), nil
+	})
+}
+
+// Insert inserts `val` before the element `i` of the array at `path`, `i`
+// may be the length of the array to append, and counts from the end when
+// negative
+func (j *AnyValue) Insert(path string, i int, val interface{}) error {
+	return j.modifyArray(path, false, func(arr []interface{}) ([]interface{}, error) {
+		k := i
+		if k < 0 {
+			k += len(arr)
+		}
+		if k < 0 || k > len(arr) {
+			return nil, indexError(i, len(arr))
+		}
+		a := make([]interface{}, 0, len(arr)+1)
+		a = append(append(a, arr[:k]...), val)
+		return append(a, arr[k:]...), nil
+	})
+}
+
+// RemoveAt removes the element `i` of the array at `path`, shifting the
+// following ones down. A negative `i` counts from the end.
+func (j *AnyValue) RemoveAt(path string, i int) error {
+	return j.modifyArray(path, false, func(arr []interface{}) ([]interface{}, error) {
+		k, ok := PathSegment{Kind: SegmentIndex, Index: i}.arrayIndex(len(arr))
+		if !ok {
+			return nil, indexError(i, len(arr))
+		}
+		a := make([]interface{}, 0, len(arr)-1)
+		return append(append(a, arr[:k]...), arr[k+1:]...), nil
+	})
+}
+
+// Swap exchanges the elements `a` and `b` of the array at `path`, negative
+// indexes count from the end
+func (j *AnyValue) Swap(path string, a, b int) error {
+	return j.modifyArray(path, false, func(arr []interface{}) ([]interface{}, error) {
+		x, ok := PathSegment{Kind: SegmentIndex, Index: a}.arrayIndex(len(arr))
+		if !ok {
+			return nil, indexError(a, len(arr))
+		}
+		y, ok := PathSegment{Kind: SegmentIndex, Index: b}.arrayIndex(len(arr))
+		if !ok {
+			return nil, indexError(b, len(arr))
+		}
+		out := append([]interface{}{}, arr...)
+		out[x], out[y] = out[y], out[x]
+		return out, nil
+	})
+}
+
+// modifyArray stores what `fn` returns for the array at `path`. `fn` gets the
+// array in place and must return a new slice, so that values sharing the old
+// one, such as the result of Array, do not change. A missing array is an
+// empty one when `create` is set.
+func (j *AnyValue) modifyArray(path string, create bool, fn func([]interface{}) ([]interface{}, error)) error {
+	p, err := ParsePath(path)
+	if err != nil {
+		return err
+	}
+	v := j.getPath(p)
+	var arr []interface{}
+	if v.miss == nil || !create {
+		if arr, err = v.Array(); err != nil {
+			return err
+		}
+	}
+	out, err := fn(arr)
+	if err != nil {
+		if pe, ok := err.(*PathError); ok {
+			pe.Path = v.path.String()
+		}
+		return err
+	}
+	return j.setPath(p, out, newSetOptions(nil))
+}
+
+// indexError reports an index outside an array of length `n`, the caller
+// sets the path
+func indexError(i, n int) error {
+	return &PathError{
+		Expected: Array,
+		Actual:   Array,
+		Msg:      fmt.Sprintf("index %d out of range for length %d", i, n),
+		Err:      ErrNotFound,
+	}
+}
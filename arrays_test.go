@@ -0,0 +1,148 @@
+package anyvalue
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStrictArrays(t *testing.T) {
+	doc, err := NewFromYaml([]byte(`
+hosts: [a, b, 3]
+ports: [80, 443, "8080"]
+ratios: [0.5, 1, x]
+flags: [true, false]
+ids: [1, -2]
+timeouts: [1s, 90, 2m]
+servers: [{addr: a}, {addr: b}]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := doc.Get("hosts").StrArr(); err == nil || err.Error() != "hosts[2]: expected string, got number" {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := doc.Get("ports").Int64Arr(); err == nil || err.Error() != "ports[2]: expected number, got string" {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := doc.Get("ratios").Float64Arr(); err == nil || err.Error() != "ratios[2]: expected number, got string" {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := doc.Get("ids").UInt64Arr(); err == nil || err.Error() != "ids[1]: -2 is negative, cannot convert to uint64" {
+		t.Fatalf("err = %v", err)
+	}
+	if a, err := doc.Get("flags").BoolArr(); err != nil || len(a) != 2 || !a[0] {
+		t.Fatalf("BoolArr() = %v, %v", a, err)
+	}
+	if _, err := doc.Get("hosts").BoolArr(); err == nil || err.Error() != "hosts[0]: expected bool, got string" {
+		t.Fatalf("err = %v", err)
+	}
+	if a, err := doc.Get("timeouts").DurationArr(); err != nil || a[1] != 90*time.Second || a[2] != 2*time.Minute {
+		t.Fatalf("DurationArr() = %v, %v", a, err)
+	}
+	if a, err := doc.Get("servers").MapArr(); err != nil || a[1]["addr"] != "b" {
+		t.Fatalf("MapArr() = %v, %v", a, err)
+	}
+	if _, err := doc.Get("hosts").MapArr(); err == nil {
+		t.Fatal("expected error")
+	}
+
+	elems, err := doc.Get("servers").Elems()
+	if err != nil || len(elems) != 2 || elems[1].Get("addr").AsStr() != "b" || elems[1].Path() != "servers[1]" {
+		t.Fatalf("Elems() = %v, %v", elems, err)
+	}
+
+	lenient := doc.WithCoercion(Lenient)
+	if a, err := lenient.Get("hosts").StrArr(); err != nil || len(a) != 2 {
+		t.Fatalf("StrArr() = %v, %v", a, err)
+	}
+	if a, err := lenient.Get("ports").Int64Arr(); err != nil || a[2] != 0 {
+		t.Fatalf("Int64Arr() = %v, %v", a, err)
+	}
+	if a := doc.WithConversion(CommonConversion).Get("ports").AsInt64Arr(); len(a) != 3 || a[2] != 8080 {
+		t.Fatalf("AsInt64Arr() = %v", a)
+	}
+}
+
+func TestArrayMutations(t *testing.T) {
+	src := New().Set("servers", []interface{}{"a", "b"})
+	js, _ := src.EncodeJson()
+	ym, _ := src.EncodeYaml()
+	mp, _ := src.EncodeMsgPack()
+	fromJSON, _ := NewFromJson(js)
+	fromYAML, _ := NewFromYaml(ym)
+	fromMsgPack, _ := NewFromMsgPack(mp)
+
+	for name, doc := range map[string]*AnyValue{"json": fromJSON, "yaml": fromYAML, "msgpack": fromMsgPack} {
+		held := doc.Get("servers").AsArray()
+		if err := doc.Append("servers", "c", "d"); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err := doc.Insert("servers", 0, "z"); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err := doc.RemoveAt("servers", -1); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err := doc.Swap("servers", 1, 2); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		got := doc.Get("servers").AsStrArr()
+		if strings.Join(got, ",") != "z,b,a,c" {
+			t.Fatalf("%s: servers = %v", name, got)
+		}
+		if len(held) != 2 || held[0] != "a" {
+			t.Fatalf("%s: array held before the mutations changed to %v", name, held)
+		}
+		if n := doc.Get("servers").Len(); n != 4 {
+			t.Fatalf("%s: Len = %d", name, n)
+		}
+	}
+
+	doc := New()
+	if err := doc.Append("a.tags", "x"); err != nil || doc.Get("a.tags[0]").AsStr() != "x" {
+		t.Fatalf("Append on a missing array: %v", err)
+	}
+	if err := doc.Insert("a.tags", 5, "y"); err == nil || err.Error() != "a.tags: index 5 out of range for length 1" || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("err = %v", err)
+	}
+	if err := doc.Insert("a.tags", -10, "y"); err == nil || err.Error() != "a.tags: index -10 out of range for length 1" {
+		t.Fatalf("err = %v", err)
+	}
+	if err := doc.RemoveAt("a.nope", 0); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("err = %v", err)
+	}
+	doc.Set("a.name", "n")
+	if err := doc.Append("a.name", "x"); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("err = %v", err)
+	}
+	if n := doc.Get("a").Len(); n != 2 {
+		t.Fatalf("Len = %d", n)
+	}
+	if n := doc.Get("a.name").Len(); n != 0 {
+		t.Fatalf("Len = %d", n)
+	}
+}
+
+func TestSlice(t *testing.T) {
+	doc := New().Set("xs", []interface{}{1, 2, 3, 4})
+	s, err := doc.Get("xs").Slice(1, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.AsInt64Arr(); len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Fatalf("Slice = %v", got)
+	}
+	s.Set("[0]", 9)
+	if doc.Get("xs[1]").AsInt() != 2 {
+		t.Fatal("modifying a slice modified the array")
+	}
+	if _, err := doc.Get("xs").Slice(3, 5); err == nil || err.Error() != "xs: slice [3:5] out of range for length 4" {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := doc.Get("missing").Slice(0, 0); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package anyvalue
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Conversion holds the rules a view made WithConversion follows to read
+// strings as numbers and bools, and to render numbers and bools as strings
+type Conversion struct {
+	// TrueWords and FalseWords are the strings Bool accepts, compared
+	// without regard to case
+	TrueWords  []string
+	FalseWords []string
+	// TrimSpace ignores the white space around strings read as numbers or
+	// bools
+	TrimSpace bool
+}
+
+// CommonConversion accepts the usual spellings of booleans in config files
+// and environment variables
+var CommonConversion = Conversion{
+	TrueWords:  []string{"true", "yes", "on", "y", "1"},
+	FalseWords: []string{"false", "no", "off", "n", "0"},
+	TrimSpace:  true,
+}
+
+// WithConversion returns a view of the value, sharing its data, whose
+// accessors and those of the values looked up from it convert scalars
+// following `c`:
+//
+//   - numeric accessors, such as Int, Float64 or Decimal, accept strings
+//     holding a number in JSON notation ("100", "-1.5", "2e3"); the
+//     coercion of the view still applies, "1.5" is not an Int under Strict
+//   - Bool accepts the strings listed in TrueWords and FalseWords
+//   - Str renders numbers as they were written and bools as "true" and
+//     "false"
+//
+// Other accessors are unchanged:
+//
+//	env.WithConversion(anyvalue.CommonConversion).Get("DEBUG").AsBool() // "yes" -> true
+func (j *AnyValue) WithConversion(c Conversion) *AnyValue {
+	c.TrueWords = append([]string(nil), c.TrueWords...)
+	c.FalseWords = append([]string(nil), c.FalseWords...)
+	o := j.options()
+	o.conversion = &c
+	return j.view(o)
+}
+
+func (c *Conversion) trim(s string) string {
+	if c.TrimSpace {
+		return strings.TrimSpace(s)
+	}
+	return s
+}
+
+// parseDecimal reads a string holding a number
+func (j *AnyValue) parseDecimal(s string) (Decimal, error) {
+	d, err := ParseDecimal(j.options().conversion.trim(s))
+	if errors.Is(err, ErrOverflow) {
+		return Decimal{}, j.outOfRange(s)
+	}
+	if err != nil {
+		return Decimal{}, j.invalid(Number, "cannot convert %q to a number", s)
+	}
+	return d, nil
+}
+
+// parseNumber reads a string holding a number as an exact value
+func (j *AnyValue) parseNumber(s string) (*big.Rat, error) {
+	if _, err := j.parseDecimal(s); err != nil {
+		return nil, err
+	}
+	r, ok := new(big.Rat).SetString(j.options().conversion.trim(s))
+	if !ok {
+		return nil, j.invalid(Number, "cannot convert %q to a number", s)
+	}
+	return r, nil
+}
+
+// parseBool reads a string holding one of the words of the conversion
+func (j *AnyValue) parseBool(s string) (bool, error) {
+	c := j.options().conversion
+	t := c.trim(s)
+	for _, w := range c.TrueWords {
+		if strings.EqualFold(t, w) {
+			return true, nil
+		}
+	}
+	for _, w := range c.FalseWords {
+		if strings.EqualFold(t, w) {
+			return false, nil
+		}
+	}
+	return false, j.invalid(Bool, "cannot convert %q to a bool", s)
+}
+
+// formatScalar renders a number or a bool, numbers keep the digits they
+// were decoded with
+func formatScalar(v interface{}) (string, bool) {
+	switch n := v.(type) {
+	case bool:
+		return strconv.FormatBool(n), true
+	case json.Number:
+		return string(n), true
+	case Decimal:
+		return n.String(), true
+	case *big.Int:
+		return n.String(), true
+	case *big.Float:
+		return n.Text('g', -1), true
+	case float32:
+		return strconv.FormatFloat(float64(n), 'g', -1, 32), true
+	case float64:
+		return strconv.FormatFloat(n, 'g', -1, 64), true
+	}
+	if r, ok := toRat(v); ok {
+		return r.Num().String(), true
+	}
+	return "", false
+}
@@ -0,0 +1,51 @@
+package anyvalue
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConversion(t *testing.T) {
+	doc, err := NewFromYaml([]byte("port: '100'\nratio: ' 1.5 '\ndebug: 'Yes'\nverbose: off\nhex: '0x10'\nmaxconn: 100\nprice: 19.90\nenabled: true\nname: web\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.Get("port").AsInt(-1) != -1 || doc.Get("debug").AsBool(false) || doc.Get("maxconn").AsStr() != "" {
+		t.Fatal("strings converted without WithConversion")
+	}
+
+	env := doc.WithConversion(CommonConversion)
+	if env.Get("port").AsInt() != 100 || env.Get("ratio").AsFloat64() != 1.5 {
+		t.Fatal("numeric strings not converted")
+	}
+	if !env.Get("debug").AsBool() || env.Get("verbose").AsBool(true) {
+		t.Fatal("bool words not converted")
+	}
+	if env.Get("maxconn").AsStr() != "100" || env.Get("price").AsStr() != "19.9" || env.Get("enabled").AsStr() != "true" {
+		t.Fatal("scalars not rendered")
+	}
+	if _, err := env.Get("ratio").Int(); err == nil || !strings.Contains(err.Error(), "not an integer") {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := env.Get("hex").Int(); err == nil || !strings.Contains(err.Error(), `hex: cannot convert "0x10"`) {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := env.Get("name").Bool(); err == nil {
+		t.Fatal("expected bool conversion error")
+	}
+	if d, err := env.Get("ratio").Decimal(); err != nil || d.String() != "1.5" {
+		t.Fatalf("Decimal() = %v, %v", d, err)
+	}
+
+	strict := doc.WithConversion(Conversion{TrueWords: []string{"enabled"}})
+	if _, err := strict.Get("debug").Bool(); err == nil {
+		t.Fatal("custom words ignored")
+	}
+	if _, err := strict.Get("ratio").Float64(); err == nil {
+		t.Fatal("white space trimmed without TrimSpace")
+	}
+	if env.WithCoercion(Lenient).Get("ratio").AsInt() != 1 {
+		t.Fatal("coercion view dropped the conversion")
+	}
+}
@@ -0,0 +1,114 @@
+package anyvalue
+
+// Cursor is a handle on the section of a document found at a path. It stays
+// bound to the root document: reads resolve the path again every time and
+// writes go through the root, so changes made through the cursor, including
+// replacing the whole section, are reflected in the root document.
+//
+// useful to hand a component only its part of the config:
+//
+//	redis := config.Sub("redis")
+//	redis.Set("max_conn", 200)
+//	config.Get("redis.max_conn").AsInt() // 200
+type Cursor struct {
+	root *AnyValue
+	path Path
+	err  error
+}
+
+// Sub returns a Cursor bound to `path` below j, the path does not have to
+// exist yet
+func (j *AnyValue) Sub(path string) *Cursor {
+	p, err := ParsePath(path)
+	return &Cursor{root: j, path: p, err: err}
+}
+
+// Sub returns a Cursor for `path` below the cursor, bound to the same root
+func (c *Cursor) Sub(path string) *Cursor {
+	p, err := c.join(path)
+	return &Cursor{root: c.root, path: p, err: err}
+}
+
+// Root returns the document the cursor is bound to
+func (c *Cursor) Root() *AnyValue {
+	return c.root
+}
+
+// Path returns the path of the cursor relative to its root
+func (c *Cursor) Path() string {
+	return c.path.String()
+}
+
+// Err returns the error for a cursor created with a malformed path
+func (c *Cursor) Err() error {
+	return c.err
+}
+
+// join appends a relative path expression to the cursor path
+func (c *Cursor) join(path string) (Path, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	p, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(Path{}, c.path...), p...), nil
+}
+
+// Value returns the current value of the section
+func (c *Cursor) Value() *AnyValue {
+	return c.Get("")
+}
+
+// Get returns the value at `path` relative to the cursor
+func (c *Cursor) Get(path string) *AnyValue {
+	p, err := c.join(path)
+	if err != nil {
+		return c.root.missingPath(path, err)
+	}
+	return c.root.getPath(p)
+}
+
+// Exist is like Get and reports whether the value was found
+func (c *Cursor) Exist(path string) (*AnyValue, bool) {
+	v := c.Get(path)
+	return v, v.miss == nil
+}
+
+// Has reports whether `path` relative to the cursor addresses a value
+func (c *Cursor) Has(path string) bool {
+	_, ok := c.Exist(path)
+	return ok
+}
+
+// Set writes `val` at `path` relative to the cursor, see AnyValue.Set
+func (c *Cursor) Set(path string, val interface{}, opts ...SetOption) *Cursor {
+	c.TrySet(path, val, opts...)
+	return c
+}
+
+// TrySet is like Set but reports errors
+func (c *Cursor) TrySet(path string, val interface{}, opts ...SetOption) error {
+	p, err := c.join(path)
+	if err != nil {
+		return err
+	}
+	return c.root.setPath(p, val, newSetOptions(opts))
+}
+
+// Replace replaces the whole section with `val`
+func (c *Cursor) Replace(val interface{}) error {
+	return c.TrySet("", val)
+}
+
+// Del deletes the value at `path` relative to the cursor, see AnyValue.Del.
+// Deleting the empty path removes the section from its parent, pruning
+// stops at the section.
+func (c *Cursor) Del(path string, opts ...DelOption) bool {
+	p, err := c.join(path)
+	if err != nil {
+		return false
+	}
+	return c.root.del(p, len(c.path), newDelOptions(opts))
+}
@@ -0,0 +1,50 @@
+package anyvalue
+
+import (
+	"testing"
+)
+
+func TestCursor(t *testing.T) {
+	config, err := LoadConfigYaml("./config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	redis := config.Sub("redis")
+	if redis.Get("addr").AsStr() != "127.0.0.1:6379" {
+		t.Fatal("read through cursor failed")
+	}
+
+	redis.Set("max_conn", 200).Set("pool.size", 8)
+	if config.Get("redis.max_conn").AsInt() != 200 || config.Get("redis.pool.size").AsInt() != 8 {
+		t.Fatal("Set through cursor not reflected in root")
+	}
+
+	redis.Del("password")
+	if config.Has("redis.password") {
+		t.Fatal("Del through cursor not reflected in root")
+	}
+
+	if err := redis.Replace("redis://localhost"); err != nil {
+		t.Fatal(err)
+	}
+	if config.Get("redis").AsStr() != "redis://localhost" {
+		t.Fatal("Replace through cursor not reflected in root")
+	}
+
+	config.Set("redis", map[string]interface{}{"addr": "b"})
+	if redis.Get("addr").AsStr() != "b" || redis.Value().Path() != "redis" {
+		t.Fatal("cursor did not follow the root")
+	}
+
+	tls := config.Sub("servers").Sub("[0].tls")
+	tls.Set("enabled", true)
+	if !config.Get("servers[0].tls.enabled").AsBool() || tls.Path() != "servers[0].tls" {
+		t.Fatal("nested cursor failed")
+	}
+
+	bad := config.Sub("a[")
+	if bad.Err() == nil || bad.TrySet("x", 1) == nil || bad.Has("") {
+		t.Fatal("expected malformed path errors")
+	}
+}
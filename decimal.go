@@ -0,0 +1,162 @@
+package anyvalue
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact base 10 number, unscaled × 10^-scale. Unlike a float
+// it keeps every digit and the number of fraction digits it was written
+// with, "12.50" has the unscaled value 1250 and the scale 2.
+//
+// The zero value is 0.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int32
+}
+
+// NewDecimal returns unscaled × 10^-scale
+func NewDecimal(unscaled *big.Int, scale int32) Decimal {
+	return Decimal{unscaled: new(big.Int).Set(unscaled), scale: scale}
+}
+
+// maxDecimalScale bounds the scale ParseDecimal accepts. Computing with a
+// decimal takes a power of ten as long as its scale, the bound keeps that
+// cheap for numbers read from untrusted documents.
+const maxDecimalScale = 1 << 14
+
+// ParseDecimal parses a number in JSON notation, such as "-12.50" or
+// "1.5e300". A scale beyond ±16384, such as the one of "1e-99999", is an
+// error matching ErrOverflow.
+func ParseDecimal(s string) (Decimal, error) {
+	mant, exp := s, int64(0)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var err error
+		if exp, err = strconv.ParseInt(s[i+1:], 10, 32); err != nil {
+			if ne, ok := err.(*strconv.NumError); ok && ne.Err == strconv.ErrRange {
+				return Decimal{}, fmt.Errorf("decimal %q out of range: %w", s, ErrOverflow)
+			}
+			return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+		}
+		mant = s[:i]
+	}
+	frac := ""
+	if i := strings.IndexByte(mant, '.'); i >= 0 {
+		mant, frac = mant[:i], mant[i+1:]
+	}
+	digits := strings.TrimPrefix(strings.TrimPrefix(mant, "-"), "+")
+	if digits == "" && frac == "" || !isDigits(digits) || !isDigits(frac) {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	scale := int64(len(frac)) - exp
+	if scale < -maxDecimalScale || scale > maxDecimalScale {
+		return Decimal{}, fmt.Errorf("decimal %q out of range: %w", s, ErrOverflow)
+	}
+	n, _ := new(big.Int).SetString(mant+frac, 10)
+	return Decimal{unscaled: n, scale: int32(scale)}, nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Unscaled returns a copy of the unscaled value
+func (d Decimal) Unscaled() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(d.unscaled)
+}
+
+// Scale returns the number of digits after the decimal point, negative
+// when the unscaled value is multiplied by a power of 10
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Rat returns the exact value of d, its cost grows with the scale
+func (d Decimal) Rat() *big.Rat {
+	r := new(big.Rat).SetInt(d.Unscaled())
+	p := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs32(d.scale))), nil)
+	if d.scale > 0 {
+		return r.Quo(r, new(big.Rat).SetInt(p))
+	}
+	return r.Mul(r, new(big.Rat).SetInt(p))
+}
+
+func abs32(n int32) int64 {
+	if n < 0 {
+		return -int64(n)
+	}
+	return int64(n)
+}
+
+// maxPlainScale is the largest scale String writes in plain notation, past
+// it the zeros would dwarf the digits
+const maxPlainScale = 32
+
+// String formats d in plain notation keeping its scale, such as "12.50", or
+// with an exponent when the scale is negative or above 32, such as "12e3" or
+// "5e-40"
+func (d Decimal) String() string {
+	u := d.Unscaled()
+	if d.scale <= 0 {
+		if d.scale == 0 {
+			return u.String()
+		}
+		return u.String() + "e" + strconv.FormatInt(abs32(d.scale), 10)
+	}
+	if d.scale > maxPlainScale {
+		return u.String() + "e-" + strconv.FormatInt(int64(d.scale), 10)
+	}
+	sign := ""
+	if u.Sign() < 0 {
+		sign = "-"
+		u.Neg(u)
+	}
+	digits := u.String()
+	if n := int(d.scale) + 1 - len(digits); n > 0 {
+		digits = strings.Repeat("0", n) + digits
+	}
+	point := len(digits) - int(d.scale)
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// decimalOf returns the exact decimal of a number, floats give their
+// shortest decimal representation
+func decimalOf(v interface{}) (Decimal, bool) {
+	switch n := v.(type) {
+	case Decimal:
+		return n, true
+	case *big.Int:
+		return Decimal{unscaled: new(big.Int).Set(n)}, true
+	case *big.Float:
+		if n.IsInf() {
+			return Decimal{}, false
+		}
+		d, err := ParseDecimal(n.Text('g', -1))
+		return d, err == nil
+	case json.Number:
+		d, err := ParseDecimal(string(n))
+		return d, err == nil
+	case float32:
+		d, err := ParseDecimal(strconv.FormatFloat(float64(n), 'g', -1, 32))
+		return d, err == nil
+	case float64:
+		d, err := ParseDecimal(strconv.FormatFloat(n, 'g', -1, 64))
+		return d, err == nil
+	}
+	r, ok := toRat(v)
+	if !ok || !r.IsInt() {
+		return Decimal{}, false
+	}
+	return Decimal{unscaled: new(big.Int).Set(r.Num())}, true
+}
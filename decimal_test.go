@@ -0,0 +1,105 @@
+package anyvalue
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestDecimal(t *testing.T) {
+	for in, want := range map[string]string{
+		"12.50":    "12.50",
+		"-0.005":   "-0.005",
+		".5":       "0.5",
+		"1.5e2":    "15e1",
+		"12e3":     "12e3",
+		"2.5e-3":   "0.0025",
+		"+7":       "7",
+		"0.00":     "0.00",
+		"1.50e-40": "150e-42",
+	} {
+		d, err := ParseDecimal(in)
+		if err != nil {
+			t.Fatalf("ParseDecimal(%q): %v", in, err)
+		}
+		if d.String() != want {
+			t.Fatalf("ParseDecimal(%q) = %s, want %s", in, d, want)
+		}
+	}
+	for _, in := range []string{"", "-", "1.2.3", "1e", "0x10", "1,5"} {
+		if _, err := ParseDecimal(in); err == nil {
+			t.Fatalf("ParseDecimal(%q) succeeded", in)
+		}
+	}
+
+	d, _ := ParseDecimal("12.50")
+	if d.Scale() != 2 || d.Unscaled().Int64() != 1250 || d.Rat().RatString() != "25/2" {
+		t.Fatalf("d = %v, %v", d.Unscaled(), d.Scale())
+	}
+	if (Decimal{}).String() != "0" {
+		t.Fatal("zero value is not 0")
+	}
+	if NewDecimal(big.NewInt(-5), 1).String() != "-0.5" {
+		t.Fatal("NewDecimal")
+	}
+	if s := NewDecimal(big.NewInt(1), 99999999).String(); s != "1e-99999999" {
+		t.Fatalf("String = %s", s)
+	}
+}
+
+func TestBigNumbers(t *testing.T) {
+	doc, err := NewFromJson([]byte(`{"id":123456789012345678901234567890,"price":19.990000000000000000001,"ratio":0.1,"port":80}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := doc.Get("id").BigInt()
+	if err != nil || id.String() != "123456789012345678901234567890" {
+		t.Fatalf("BigInt() = %v, %v", id, err)
+	}
+	if _, err := doc.Get("price").BigInt(); err == nil {
+		t.Fatal("expected fractional error")
+	}
+	if n, err := doc.WithCoercion(Lenient).Get("price").BigInt(); err != nil || n.Int64() != 19 {
+		t.Fatalf("BigInt() = %v, %v", n, err)
+	}
+	if d, err := doc.Get("price").Decimal(); err != nil || d.String() != "19.990000000000000000001" {
+		t.Fatalf("Decimal() = %v, %v", d, err)
+	}
+	if d, err := NewFromInf(0.1).Decimal(); err != nil || d.String() != "0.1" {
+		t.Fatalf("Decimal() = %v, %v", d, err)
+	}
+	f, err := doc.Get("price").BigFloat()
+	if err != nil || f.Text('f', 21) != "19.990000000000000000001" {
+		t.Fatalf("BigFloat() = %v, %v", f, err)
+	}
+	if _, err := doc.Get("missing").Decimal(); err == nil {
+		t.Fatal("expected error on a missing value")
+	}
+
+	doc.Set("total", new(big.Int).Lsh(big.NewInt(1), 100))
+	if doc.Get("total").Kind() != Number || !doc.Get("total").IsInteger() {
+		t.Fatal("big.Int is not an integer number")
+	}
+	if _, err := doc.Get("total").Int64(); err == nil {
+		t.Fatal("expected int64 overflow")
+	}
+	if !doc.Get("id").Equal(NewFromInf(id)) {
+		t.Fatal("json.Number and big.Int differ")
+	}
+
+	var out struct {
+		ID    *big.Int  `json:"id"`
+		Price Decimal   `json:"price"`
+		Ratio big.Float `json:"ratio"`
+		Port  *big.Int  `json:"port"`
+	}
+	if err := doc.Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	if out.ID.Cmp(id) != 0 || out.Price.String() != "19.990000000000000000001" || out.Port.Int64() != 80 {
+		t.Fatalf("out = %+v", out)
+	}
+	if r, _ := out.Ratio.Float64(); r != 0.1 {
+		t.Fatalf("Ratio = %v", r)
+	}
+}
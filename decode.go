@@ -0,0 +1,525 @@
+package anyvalue
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WeaklyTyped lets Decode convert between scalar types: strings holding a
+// number or a bool ("100", "true") into numbers and bools, numbers and
+// bools into strings, and numbers into bools (zero is false)
+func WeaklyTyped() DecodeOption {
+	return func(o *decodeOptions) {
+		o.weak = true
+	}
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// Decode stores the value in `out`, which must be a non-nil pointer, the way
+// encoding/json would: structs are filled from maps using the `json` field
+// tags, or the `yaml` tags when there is no `json` tag, matching field names
+// case-insensitively otherwise. Embedded structs and fields tagged
+// `,squash` or `,inline` read their fields from the same map.
+//
+// Decoding works on the value itself, so YAML and msgpack types are kept
+// and errors are *PathError naming the path that failed:
+//
+//	var redis RedisConfig
+//	err := config.Get("redis").Decode(&redis)
+func (j *AnyValue) Decode(out interface{}, opts ...DecodeOption) error {
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("decode: non-nil pointer expected, got %T", out)
+	}
+	if j.miss != nil {
+		return j.mismatch(kindOfType(v.Type().Elem()))
+	}
+	d := &decoder{o: newDecodeOptions(opts)}
+	return d.decode(j.path, j.data, v.Elem())
+}
+
+type decoder struct {
+	o *decodeOptions
+}
+
+func (d *decoder) fail(p Path, data interface{}, v reflect.Value, err error, format string, args ...interface{}) error {
+	return &PathError{
+		Path:     p.String(),
+		Expected: kindOfType(v.Type()),
+		Actual:   kindOf(data),
+		Msg:      fmt.Sprintf(format, args...),
+		Err:      err,
+	}
+}
+
+func (d *decoder) mismatch(p Path, data interface{}, v reflect.Value) error {
+	return d.fail(p, data, v, ErrTypeMismatch, "cannot decode %s into %s", kindOf(data), v.Type())
+}
+
+// located moves the error of an accessor run on a detached value to `p`
+func (d *decoder) located(p Path, err error) error {
+	if pe, ok := err.(*PathError); ok {
+		located := *pe
+		located.Path = p.String()
+		return &located
+	}
+	return err
+}
+
+func (d *decoder) decode(p Path, data interface{}, v reflect.Value) error {
+	if v.Kind() == reflect.Ptr {
+		if data == nil {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decode(p, data, v.Elem())
+	}
+	if data == nil {
+		// like encoding/json, null leaves the value untouched
+		return nil
+	}
+	if reflect.TypeOf(data) == v.Type() && v.Kind() != reflect.Map && v.Kind() != reflect.Slice {
+		// leaves such as time.Time that are already of the target type
+		v.Set(reflect.ValueOf(data))
+		return nil
+	}
+	switch v.Type() {
+	case durationType, timeType:
+		return d.decodeTime(p, data, v)
+	}
+	if s, ok := data.(string); ok && v.CanAddr() && reflect.PtrTo(v.Type()).Implements(textUnmarshalerType) {
+		if err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return d.fail(p, data, v, ErrTypeMismatch, "%v", err)
+		}
+		return nil
+	}
+	switch v.Type() {
+	case bigIntType.Elem(), bigFloatType.Elem(), decimalType:
+		return d.decodeBig(p, data, v)
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		val := reflect.ValueOf(deepCopy(data))
+		if !val.Type().AssignableTo(v.Type()) {
+			return d.mismatch(p, data, v)
+		}
+		v.Set(val)
+		return nil
+	case reflect.Bool:
+		return d.decodeBool(p, data, v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return d.decodeNumber(p, data, v)
+	case reflect.String:
+		return d.decodeString(p, data, v)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return d.decodeBytes(p, data, v)
+		}
+		return d.decodeList(p, data, v)
+	case reflect.Array:
+		return d.decodeList(p, data, v)
+	case reflect.Map:
+		return d.decodeMap(p, data, v)
+	case reflect.Struct:
+		return d.decodeStruct(p, data, v)
+	}
+	return d.mismatch(p, data, v)
+}
+
+func (d *decoder) decodeBool(p Path, data interface{}, v reflect.Value) error {
+	switch b := data.(type) {
+	case bool:
+		v.SetBool(b)
+		return nil
+	case string:
+		if d.o.weak {
+			parsed, err := strconv.ParseBool(b)
+			if err != nil {
+				return d.fail(p, data, v, ErrTypeMismatch, "invalid bool %q", b)
+			}
+			v.SetBool(parsed)
+			return nil
+		}
+	default:
+		if r, ok := toRat(data); ok && d.o.weak {
+			v.SetBool(r.Sign() != 0)
+			return nil
+		}
+	}
+	return d.mismatch(p, data, v)
+}
+
+// decodeTime decodes a time.Duration or a time.Time the way the Duration
+// and Time accessors read them
+func (d *decoder) decodeTime(p Path, data interface{}, v reflect.Value) error {
+	val := &AnyValue{data: data, opts: &valueOptions{coercion: Strict}}
+	var err error
+	if v.Type() == durationType {
+		var dur time.Duration
+		if dur, err = val.Duration(); err == nil {
+			v.SetInt(int64(dur))
+		}
+	} else {
+		var t time.Time
+		if t, err = val.Time(); err == nil {
+			v.Set(reflect.ValueOf(t))
+		}
+	}
+	return d.located(p, err)
+}
+
+// decodeBig decodes a number into a big.Int, a big.Float or a Decimal
+// without going through a float64
+func (d *decoder) decodeBig(p Path, data interface{}, v reflect.Value) error {
+	if s, isStr := data.(string); isStr && d.o.weak {
+		s = strings.TrimSpace(s)
+		if _, err := ParseDecimal(s); err != nil {
+			return d.fail(p, data, v, ErrTypeMismatch, "invalid number %q", s)
+		}
+		data = json.Number(s)
+	}
+	if kindOf(data) != Number {
+		return d.mismatch(p, data, v)
+	}
+
+	val := &AnyValue{data: data, opts: &valueOptions{coercion: Strict}}
+	var err error
+	switch v.Type() {
+	case decimalType:
+		var dec Decimal
+		if dec, err = val.Decimal(); err == nil {
+			v.Set(reflect.ValueOf(dec))
+		}
+	case bigIntType.Elem():
+		var n *big.Int
+		if n, err = val.BigInt(); err == nil {
+			v.Addr().Interface().(*big.Int).Set(n)
+		}
+	default:
+		var f *big.Float
+		if f, err = val.BigFloat(); err == nil {
+			v.Addr().Interface().(*big.Float).Set(f)
+		}
+	}
+	return d.located(p, err)
+}
+
+func (d *decoder) decodeNumber(p Path, data interface{}, v reflect.Value) error {
+	r, ok := toRat(data)
+	if s, isStr := data.(string); isStr && d.o.weak {
+		if r, ok = new(big.Rat).SetString(strings.TrimSpace(s)); !ok {
+			return d.fail(p, data, v, ErrTypeMismatch, "invalid number %q", s)
+		}
+	}
+	if b, isBool := data.(bool); isBool && d.o.weak {
+		r, ok = new(big.Rat), true
+		if b {
+			r.SetInt64(1)
+		}
+	}
+	if !ok {
+		return d.mismatch(p, data, v)
+	}
+
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f, _ := r.Float64()
+		if v.OverflowFloat(f) {
+			return d.fail(p, data, v, ErrOverflow, "%s overflows %s", r.RatString(), v.Type())
+		}
+		v.SetFloat(f)
+		return nil
+	}
+	if !r.IsInt() {
+		return d.fail(p, data, v, ErrTypeMismatch, "%s is not an integer", r.FloatString(6))
+	}
+	n := r.Num()
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if !n.IsUint64() || v.OverflowUint(n.Uint64()) {
+			return d.fail(p, data, v, ErrOverflow, "%s overflows %s", n, v.Type())
+		}
+		v.SetUint(n.Uint64())
+	default:
+		if !n.IsInt64() || v.OverflowInt(n.Int64()) {
+			return d.fail(p, data, v, ErrOverflow, "%s overflows %s", n, v.Type())
+		}
+		v.SetInt(n.Int64())
+	}
+	return nil
+}
+
+func (d *decoder) decodeString(p Path, data interface{}, v reflect.Value) error {
+	switch s := data.(type) {
+	case string:
+		v.SetString(s)
+		return nil
+	case bool:
+		if d.o.weak {
+			v.SetString(strconv.FormatBool(s))
+			return nil
+		}
+	default:
+		if r, ok := toRat(data); ok && d.o.weak {
+			v.SetString(formatRat(r))
+			return nil
+		}
+	}
+	return d.mismatch(p, data, v)
+}
+
+// formatRat renders an exact number in its shortest decimal form
+func formatRat(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	f, _ := r.Float64()
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// decodeBytes decodes binary data or a base64 string, as encoding/json
+// does, into a byte slice
+func (d *decoder) decodeBytes(p Path, data interface{}, v reflect.Value) error {
+	switch b := data.(type) {
+	case []byte:
+		v.SetBytes(append([]byte(nil), b...))
+		return nil
+	case string:
+		decoded, err := decodeBase64(b)
+		if err != nil {
+			return d.fail(p, data, v, ErrTypeMismatch, "invalid base64 data")
+		}
+		v.SetBytes(decoded)
+		return nil
+	}
+	return d.decodeList(p, data, v)
+}
+
+func (d *decoder) decodeList(p Path, data interface{}, v reflect.Value) error {
+	a, ok := data.([]interface{})
+	if !ok {
+		return d.mismatch(p, data, v)
+	}
+	if v.Kind() == reflect.Array {
+		if len(a) > v.Len() {
+			return d.fail(p, data, v, ErrOverflow, "%d elements do not fit into %s", len(a), v.Type())
+		}
+	} else {
+		v.Set(reflect.MakeSlice(v.Type(), len(a), len(a)))
+	}
+	for i, e := range a {
+		ep := append(append(Path{}, p...), PathSegment{Kind: SegmentIndex, Index: i})
+		if err := d.decode(ep, e, v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *decoder) decodeMap(p Path, data interface{}, v reflect.Value) error {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return d.mismatch(p, data, v)
+	}
+	t := v.Type()
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(t, len(m)))
+	}
+	for k, e := range m {
+		ep := append(append(Path{}, p...), PathSegment{Kind: SegmentKey, Key: k})
+		key := reflect.New(t.Key()).Elem()
+		if err := d.decodeKey(ep, k, key); err != nil {
+			return err
+		}
+		val := reflect.New(t.Elem()).Elem()
+		if err := d.decode(ep, e, val); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, val)
+	}
+	return nil
+}
+
+// decodeKey converts a map key into the key type of the target map
+func (d *decoder) decodeKey(p Path, k string, key reflect.Value) error {
+	if reflect.PtrTo(key.Type()).Implements(textUnmarshalerType) {
+		if err := key.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(k)); err != nil {
+			return d.fail(p, k, key, ErrTypeMismatch, "invalid key: %v", err)
+		}
+		return nil
+	}
+	switch key.Kind() {
+	case reflect.String:
+		key.SetString(k)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if _, ok := new(big.Rat).SetString(k); !ok {
+			return d.fail(p, k, key, ErrTypeMismatch, "invalid key %q for %s", k, key.Type())
+		}
+		return d.decodeNumber(p, json.Number(k), key)
+	}
+	return d.fail(p, k, key, ErrTypeMismatch, "unsupported map key type %s", key.Type())
+}
+
+func (d *decoder) decodeStruct(p Path, data interface{}, v reflect.Value) error {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return d.mismatch(p, data, v)
+	}
+	for _, f := range structFields(v.Type()) {
+		key, e, ok := f.lookup(m)
+		if !ok {
+			continue
+		}
+		fv, err := fieldByIndex(v, f.index)
+		if err != nil {
+			return d.fail(p, data, v, ErrTypeMismatch, "%v", err)
+		}
+		ep := append(append(Path{}, p...), PathSegment{Kind: SegmentKey, Key: key})
+		if err := d.decode(ep, e, fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldByIndex returns the nested field, allocating nil embedded pointers
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return v, fmt.Errorf("cannot set embedded pointer to unexported struct %s", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// field describes a struct field reachable from a map key
+type field struct {
+	name      string
+	tagged    bool
+	index     []int
+	omitEmpty bool
+}
+
+// lookup finds the map entry for the field, an exact match on the name
+// first, then a case-insensitive one for untagged fields
+func (f *field) lookup(m map[string]interface{}) (string, interface{}, bool) {
+	if e, ok := m[f.name]; ok {
+		return f.name, e, true
+	}
+	if f.tagged {
+		return "", nil, false
+	}
+	for k, e := range m {
+		if strings.EqualFold(k, f.name) {
+			return k, e, true
+		}
+	}
+	return "", nil, false
+}
+
+// structTag returns the `json` tag of a field, or its `yaml` tag
+func structTag(sf reflect.StructField) (string, bool) {
+	if tag, ok := sf.Tag.Lookup("json"); ok {
+		return tag, true
+	}
+	return sf.Tag.Lookup("yaml")
+}
+
+// structFields lists the fields of a struct type, flattening embedded
+// structs and `,squash`/`,inline` fields; outer fields win over inner ones
+func structFields(t reflect.Type) []field {
+	var fields []field
+	seen := map[string]bool{}
+	level := []field{{index: nil}}
+	types := []reflect.Type{t}
+	// a type embedded again at a deeper level only adds hidden fields, and
+	// recursive embeddings such as struct{ *T } would never end
+	visited := map[reflect.Type]bool{}
+
+	for len(level) > 0 {
+		var next []field
+		var nextTypes []reflect.Type
+		var found []field
+		for i, parent := range level {
+			st := types[i]
+			if visited[st] {
+				continue
+			}
+			visited[st] = true
+			for x := 0; x < st.NumField(); x++ {
+				sf := st.Field(x)
+				tag, tagged := structTag(sf)
+				opts := strings.Split(tag, ",")
+				name := opts[0]
+				if name == "-" && len(opts) == 1 {
+					continue
+				}
+				index := append(append([]int{}, parent.index...), x)
+
+				ft := sf.Type
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				flatten := hasTagOption(opts[1:], "squash") || hasTagOption(opts[1:], "inline") ||
+					sf.Anonymous && name == ""
+				if flatten && ft.Kind() == reflect.Struct {
+					next = append(next, field{index: index})
+					nextTypes = append(nextTypes, ft)
+					continue
+				}
+				if sf.PkgPath != "" {
+					// unexported
+					continue
+				}
+				if name == "" {
+					name = sf.Name
+				}
+				found = append(found, field{
+					name:      name,
+					tagged:    tagged && opts[0] != "",
+					index:     index,
+					omitEmpty: hasTagOption(opts[1:], "omitempty"),
+				})
+			}
+		}
+		for _, f := range found {
+			if !seen[f.name] {
+				seen[f.name] = true
+				fields = append(fields, f)
+			}
+		}
+		level, types = next, nextTypes
+	}
+	return fields
+}
+
+func hasTagOption(opts []string, name string) bool {
+	for _, o := range opts {
+		if o == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package anyvalue
+
+import (
+	"net"
+	"testing"
+)
+
+type redisConfig struct {
+	Addr        string `json:"addr"`
+	Password    string `yaml:"password"`
+	DB          int
+	MaxConn     uint16 `json:"max_conn"`
+	MaxIdleConn *int   `json:"max_idle_conn"`
+}
+
+type common struct {
+	Listen string `json:"listen"`
+}
+
+type ginConfig struct {
+	Mode string `yaml:"mode"`
+	Log  string `yaml:"log"`
+}
+
+type appConfig struct {
+	common
+	Redis   redisConfig            `json:"redis"`
+	Gin     ginConfig              `json:",squash"`
+	MySQL   map[string]interface{} `json:"mysql"`
+	Ignored string                 `json:"-"`
+}
+
+func TestDecode(t *testing.T) {
+	config, err := LoadConfigYaml("./config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Set("mode", "release").Set("Ignored", "x")
+
+	var app appConfig
+	if err := config.Decode(&app); err != nil {
+		t.Fatal(err)
+	}
+	if app.Listen != ":8081" || app.Redis.Addr != "127.0.0.1:6379" || app.Redis.MaxConn != 100 {
+		t.Fatalf("app = %+v", app)
+	}
+	if app.Redis.MaxIdleConn == nil || *app.Redis.MaxIdleConn != 10 || app.Redis.DB != 0 {
+		t.Fatalf("redis = %+v", app.Redis)
+	}
+	if app.Gin.Mode != "release" || app.Ignored != "" || app.MySQL["max_conn"] != 100 {
+		t.Fatalf("app = %+v", app)
+	}
+
+	var redis redisConfig
+	if err := config.Get("redis").Decode(&redis); err != nil {
+		t.Fatal(err)
+	}
+	if redis.Addr != "127.0.0.1:6379" {
+		t.Fatalf("redis = %+v", redis)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	doc, err := NewFromJson([]byte(`{"servers":[{"port":80},{"port":"8080"},{"port":70000},{"port":1.5}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ports []struct {
+		Port uint16 `json:"port"`
+	}
+	err = doc.Get("servers").Decode(&ports)
+	if de, ok := err.(*PathError); !ok || de.Path != "servers[1].port" {
+		t.Fatalf("err = %v", err)
+	}
+
+	if err := doc.Get("servers").Decode(&ports, WeaklyTyped()); err == nil {
+		t.Fatal("expected overflow error")
+	} else if de := err.(*PathError); de.Path != "servers[2].port" {
+		t.Fatalf("err = %v", err)
+	}
+
+	var port int
+	if err := doc.Get("servers[3].port").Decode(&port); err == nil {
+		t.Fatal("expected fractional error")
+	}
+	if err := doc.Get("servers[9].port").Decode(&port); err == nil {
+		t.Fatal("expected not found error")
+	}
+	if err := doc.Decode(port); err == nil {
+		t.Fatal("expected non-pointer error")
+	}
+}
+
+func TestDecodeWeak(t *testing.T) {
+	doc, err := NewFromYaml([]byte("port: '100'\nenabled: 'true'\nname: 42\nroute: 10.0.0.1\nlimits: {1: a, 2: b}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out struct {
+		Port    int
+		Enabled bool
+		Name    string
+		Route   net.IP
+		Limits  map[int]string
+	}
+	if err := doc.Decode(&out); err == nil {
+		t.Fatal("expected error without weak typing")
+	}
+	if err := doc.Decode(&out, WeaklyTyped()); err != nil {
+		t.Fatal(err)
+	}
+	if out.Port != 100 || !out.Enabled || out.Name != "42" || !out.Route.Equal(net.IPv4(10, 0, 0, 1)) || out.Limits[2] != "b" {
+		t.Fatalf("out = %+v", out)
+	}
+}
+
+type recursive struct {
+	*recursive
+	X int `json:"x"`
+}
+
+func TestDecodeRecursiveEmbedding(t *testing.T) {
+	var out recursive
+	if err := New().Set("x", 3).Decode(&out); err != nil || out.X != 3 {
+		t.Fatalf("out = %+v, err = %v", out, err)
+	}
+	v, err := FromValue(recursive{X: 4})
+	if err != nil || v.Get("x").AsInt() != 4 {
+		t.Fatalf("FromValue = %v, %v", v, err)
+	}
+}
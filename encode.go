@@ -0,0 +1,174 @@
+package anyvalue
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+type encodeFormat int
+
+const (
+	formatJSON encodeFormat = iota
+	formatYAML
+	formatMsgPack
+)
+
+// encoder rewrites a tree into one the marshaler of a format handles
+// without losing precision:
+//
+//   - JSON writes json.Number, *big.Int, *big.Float and Decimal values as
+//     json.Number
+//   - msgpack writes them as ints and floats when that is exact, and as the
+//     MsgPackDecimalExt extension otherwise
+//   - YAML writes them as ints and floats when that is exact, and otherwise
+//     as a placeholder string that is replaced by the number in the output
+//
+// A []byte is a bin in msgpack, a !!binary value in
+// YAML, both of which decode to a []byte again, and a base64 string in JSON,
+// which Base64Bytes reads back.
+type encoder struct {
+	format encodeFormat
+	nonce  string
+	// placeholders maps the YAML placeholders to the text replacing them
+	placeholders map[string]string
+}
+
+func newEncoder(f encodeFormat) *encoder {
+	return &encoder{format: f}
+}
+
+func (e *encoder) encodable(v interface{}) (interface{}, error) {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(c))
+		for k, x := range c {
+			n, err := e.encodable(x)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = n
+		}
+		return m, nil
+	case map[interface{}]interface{}:
+		m := make(map[interface{}]interface{}, len(c))
+		for k, x := range c {
+			n, err := e.encodable(x)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = n
+		}
+		return m, nil
+	case []interface{}:
+		a := make([]interface{}, len(c))
+		for i, x := range c {
+			n, err := e.encodable(x)
+			if err != nil {
+				return nil, err
+			}
+			a[i] = n
+		}
+		return a, nil
+	case json.Number, *big.Int, *big.Float, Decimal:
+		return e.number(v)
+	case []byte:
+		if e.format == formatYAML {
+			return e.placeholder("!!binary " + base64.StdEncoding.EncodeToString(c)), nil
+		}
+	}
+	return v, nil
+}
+
+func (e *encoder) number(v interface{}) (interface{}, error) {
+	if f, ok := v.(*big.Float); ok && f.IsInf() {
+		return nil, fmt.Errorf("cannot encode %v", f)
+	}
+	if e.format == formatJSON {
+		if d, ok := v.(Decimal); ok {
+			return json.Number(d.String()), nil
+		}
+		if n, ok := v.(json.Number); ok {
+			return n, nil
+		}
+		if f, ok := v.(*big.Float); ok {
+			// String rounds to 10 digits
+			return json.Number(f.Text('g', -1)), nil
+		}
+		return json.Number(v.(*big.Int).String()), nil
+	}
+
+	d, ok := decimalOf(v)
+	if !ok {
+		n := v.(json.Number)
+		if _, err := ParseDecimal(string(n)); errors.Is(err, ErrOverflow) {
+			return nil, fmt.Errorf("cannot encode %s: %w", n, err)
+		}
+		// a json.Number that is not a number, keep its text
+		return string(n), nil
+	}
+	if n, ok := nativeNumber(d); ok {
+		return n, nil
+	}
+	if e.format == formatMsgPack {
+		return msgpackDecimal(d), nil
+	}
+	return e.placeholder(d.String()), nil
+}
+
+// placeholder returns a string that restore replaces by `text` in the YAML
+// output
+func (e *encoder) placeholder(text string) string {
+	if e.placeholders == nil {
+		b := make([]byte, 8)
+		rand.Read(b)
+		e.nonce = hex.EncodeToString(b)
+		e.placeholders = make(map[string]string)
+	}
+	token := fmt.Sprintf("anyvalue_placeholder_%s_%d_", e.nonce, len(e.placeholders))
+	e.placeholders[token] = text
+	return token
+}
+
+// restore replaces the YAML placeholders in the output by their text
+func (e *encoder) restore(out []byte) []byte {
+	for token, text := range e.placeholders {
+		out = bytes.Replace(out, []byte(token), []byte(text), 1)
+	}
+	return out
+}
+
+// maxNativeScale bounds the decimals nativeNumber converts to a big.Rat,
+// the shortest form of a float64 never has a larger scale
+const maxNativeScale = 400
+
+// nativeNumber returns d as an int64, an uint64 or a float64 when that
+// type holds it exactly, a float is exact when it formats back to d
+func nativeNumber(d Decimal) (interface{}, bool) {
+	if d.scale > maxNativeScale || d.scale < -maxNativeScale {
+		return nil, false
+	}
+	r := d.Rat()
+	if r.IsInt() {
+		n := r.Num()
+		if n.IsInt64() {
+			return n.Int64(), true
+		}
+		if n.IsUint64() {
+			return n.Uint64(), true
+		}
+		return nil, false
+	}
+	f, _ := r.Float64()
+	back, err := ParseDecimal(strconv.FormatFloat(f, 'g', -1, 64))
+	if err != nil || back.Rat().Cmp(r) != 0 {
+		return nil, false
+	}
+	return f, true
+}
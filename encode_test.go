@@ -0,0 +1,178 @@
+package anyvalue
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestEncodeBigNumbers(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	price, _ := ParseDecimal("19.990000000000000000001")
+	doc := New().
+		Set("id", huge).
+		Set("price", price).
+		Set("pi", new(big.Float).SetPrec(200).SetFloat64(3.25)).
+		Set("small", json.Number("42")).
+		Set("ratio", json.Number("0.5"))
+
+	js, err := doc.EncodeJson()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"id":123456789012345678901234567890`, `"price":19.990000000000000000001`, `"pi":3.25`} {
+		if !strings.Contains(string(js), want) {
+			t.Fatalf("json = %s, missing %s", js, want)
+		}
+	}
+
+	yml, err := doc.EncodeYaml()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"id: 123456789012345678901234567890\n", "price: 19.990000000000000000001\n", "small: 42\n", "ratio: 0.5\n"} {
+		if !strings.Contains(string(yml), want) {
+			t.Fatalf("yaml = %s, missing %s", yml, want)
+		}
+	}
+
+	mp, err := doc.EncodeMsgPack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := NewFromMsgPack(mp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !back.Equal(doc) {
+		t.Fatalf("msgpack round trip = %v", back.Interface())
+	}
+	if _, ok := back.Get("small").Interface().(int64); !ok {
+		t.Fatalf("small = %T", back.Get("small").Interface())
+	}
+	if d, err := back.Get("price").Decimal(); err != nil || d.String() != price.String() {
+		t.Fatalf("price = %v, %v", d, err)
+	}
+
+	long, _, _ := big.ParseFloat("3.14159265358979323846264338327950288", 10, 200, big.ToNearestEven)
+	if js, err := New().Set("pi", long).EncodeJson(); err != nil || string(js) != `{"pi":3.14159265358979323846264338327950288}` {
+		t.Fatalf("json = %s, %v", js, err)
+	}
+
+	if _, err := New().Set("inf", new(big.Float).SetInf(false)).EncodeJson(); err == nil {
+		t.Fatal("expected error on infinity")
+	}
+}
+
+func TestEncodeTinyDecimal(t *testing.T) {
+	doc := New().Set("a", NewDecimal(big.NewInt(1), 99999999))
+	mp, err := doc.EncodeMsgPack()
+	if err != nil || len(mp) > 32 {
+		t.Fatalf("EncodeMsgPack = %d bytes, %v", len(mp), err)
+	}
+	ym, err := doc.EncodeYaml()
+	if err != nil || string(ym) != "a: 1e-99999999\n" {
+		t.Fatalf("EncodeYaml = %q, %v", ym, err)
+	}
+}
+
+func TestDecimalScaleBound(t *testing.T) {
+	doc, err := NewFromJson([]byte(`{"a":1e-99999999}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := doc.Get("a").Int(); err == nil {
+		t.Fatal("expected an error reading 1e-99999999 as an int")
+	}
+	if _, err := doc.EncodeMsgPack(); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("EncodeMsgPack: %v", err)
+	}
+
+	// a msgpack decimal extension with a huge scale
+	mp, err := New().Set("a", NewDecimal(big.NewInt(1), 99999999)).EncodeMsgPack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFromMsgPack(mp); err == nil {
+		t.Fatal("expected an error decoding an out of range decimal")
+	}
+}
+
+func TestEncodeBinary(t *testing.T) {
+	blob := []byte{0xde, 0xad, 0xbe, 0xef, 'h', 'i'}
+	doc := New().Set("blob", blob).Set("text", []byte("hello"))
+
+	yml, err := doc.EncodeYaml()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(yml), "blob: !!binary 3q2+72hp\n") {
+		t.Fatalf("yaml = %s", yml)
+	}
+	js, err := doc.EncodeJson()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mp, err := doc.EncodeMsgPack()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fromYaml, err := NewFromYaml(yml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromJson, err := NewFromJson(js)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromMsgPack, err := NewFromMsgPack(mp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, back := range []*AnyValue{fromYaml, fromJson, fromMsgPack} {
+		if b, err := back.Get("blob").Base64Bytes(); err != nil || !bytes.Equal(b, blob) {
+			t.Fatalf("Base64Bytes() = %v, %v", b, err)
+		}
+		if b, err := back.Get("text").Base64Bytes(); err != nil || string(b) != "hello" {
+			t.Fatalf("Base64Bytes() = %q, %v", b, err)
+		}
+	}
+	if b, err := fromMsgPack.Get("blob").Bytes(); err != nil || !bytes.Equal(b, blob) {
+		t.Fatalf("Bytes() = %v, %v", b, err)
+	}
+	for _, back := range []*AnyValue{fromYaml, fromMsgPack} {
+		if b, err := back.Get("text").Bytes(); err != nil || string(b) != "hello" || back.Get("text").Kind() != Binary {
+			t.Fatalf("Bytes() = %q, %v", b, err)
+		}
+		if !back.Equal(doc) {
+			t.Fatal("binary values changed in a round trip")
+		}
+	}
+
+	tagged, err := NewFromYaml([]byte("key: !!binary 3q2+7w==\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := tagged.Get("key").Bytes(); err != nil || !bytes.Equal(b, blob[:4]) || tagged.Get("key").Kind() != Binary {
+		t.Fatalf("Bytes() = %v, %v", b, err)
+	}
+
+	plain := New().Set("name", "web-1").Set("word", "test")
+	if _, err := plain.Get("name").Base64Bytes(); err == nil || err.Error() != "name: invalid base64 data" {
+		t.Fatalf("err = %v", err)
+	}
+	if b, err := plain.Get("word").Bytes(); err != nil || string(b) != "test" {
+		t.Fatalf("Bytes() = %q, %v", b, err)
+	}
+
+	var out struct {
+		Blob []byte `json:"blob"`
+	}
+	if err := fromJson.Decode(&out); err != nil || !bytes.Equal(out.Blob, blob) {
+		t.Fatalf("Decode() = %v, %v", out.Blob, err)
+	}
+}
@@ -0,0 +1,220 @@
+package anyvalue
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"math/big"
+	"time"
+)
+
+type equalOptions struct {
+	tolerance float64
+}
+
+// EqualOption configures Equal
+type EqualOption func(*equalOptions)
+
+// FloatTolerance makes numbers equal when they differ by at most `eps`
+func FloatTolerance(eps float64) EqualOption {
+	return func(o *equalOptions) {
+		o.tolerance = eps
+	}
+}
+
+// Clone returns an independent deep copy of the value, changes to the copy
+// are not visible in the original and the other way round
+func (j *AnyValue) Clone() *AnyValue {
+	c := &AnyValue{data: deepCopy(j.data), path: append(Path{}, j.path...), opts: j.opts}
+	if j.miss != nil {
+		miss := *j.miss
+		c.miss = &miss
+	}
+	return c
+}
+
+// Equal compares the value with `other` structurally, whatever format they
+// were decoded from: numbers are equal when their values are equal, so the
+// json.Number 1 equals the int 1 from YAML and the int8 1 from msgpack, and
+// maps are compared by keys and values.
+//
+// A missing value only equals another missing value, an explicit null only
+// equals null.
+func (j *AnyValue) Equal(other *AnyValue, opts ...EqualOption) bool {
+	if j.miss != nil || other.miss != nil {
+		return j.miss != nil && other.miss != nil
+	}
+	o := &equalOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return equalValues(j.data, other.data, o)
+}
+
+// deepCopy copies the maps, arrays and byte slices of a tree, other leaves
+// are immutable and shared
+func deepCopy(v interface{}) interface{} {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(c))
+		for k, e := range c {
+			m[k] = deepCopy(e)
+		}
+		return m
+	case []interface{}:
+		a := make([]interface{}, len(c))
+		for i, e := range c {
+			a[i] = deepCopy(e)
+		}
+		return a
+	case map[interface{}]interface{}:
+		m := make(map[interface{}]interface{}, len(c))
+		for k, e := range c {
+			m[k] = deepCopy(e)
+		}
+		return m
+	case []byte:
+		return append([]byte(nil), c...)
+	case *big.Int:
+		return new(big.Int).Set(c)
+	case *big.Float:
+		return new(big.Float).Copy(c)
+	}
+	return v
+}
+
+// deepEqual compares two trees, numbers are equal when their values are
+// equal whatever their Go type
+func deepEqual(a, b interface{}) bool {
+	return equalValues(a, b, &equalOptions{})
+}
+
+func equalValues(a, b interface{}, o *equalOptions) bool {
+	if x, ok := asStringMap(a); ok {
+		y, ok := asStringMap(b)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, e := range x {
+			f, ok := y[k]
+			if !ok || !equalValues(e, f, o) {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch x := a.(type) {
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equalValues(x[i], y[i], o) {
+				return false
+			}
+		}
+		return true
+	case nil:
+		return b == nil
+	case []byte:
+		y, ok := b.([]byte)
+		return ok && bytes.Equal(x, y)
+	case time.Time:
+		y, ok := b.(time.Time)
+		return ok && x.Equal(y)
+	}
+
+	if ra, ok := toRat(a); ok {
+		rb, ok := toRat(b)
+		if !ok {
+			return false
+		}
+		if ra.Cmp(rb) == 0 {
+			return true
+		}
+		fa, _ := ra.Float64()
+		fb, _ := rb.Float64()
+		return o.tolerance > 0 && math.Abs(fa-fb) <= o.tolerance
+	}
+	switch x := a.(type) {
+	case string:
+		y, ok := b.(string)
+		return ok && x == y
+	case bool:
+		y, ok := b.(bool)
+		return ok && x == y
+	}
+	return false
+}
+
+// asStringMap returns the map with string keys, converting YAML style maps
+func asStringMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		n, _ := normalize(deepCopy(m), newDecodeOptions(nil))
+		return n.(map[string]interface{}), true
+	}
+	return nil, false
+}
+
+// toRat converts any number to its exact rational value
+func toRat(v interface{}) (*big.Rat, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		d, err := ParseDecimal(string(n))
+		if err != nil {
+			return nil, false
+		}
+		return d.Rat(), true
+	case int:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int8:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int16:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int32:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int64:
+		return new(big.Rat).SetInt64(n), true
+	case uint:
+		return new(big.Rat).SetUint64(uint64(n)), true
+	case uint8:
+		return new(big.Rat).SetUint64(uint64(n)), true
+	case uint16:
+		return new(big.Rat).SetUint64(uint64(n)), true
+	case uint32:
+		return new(big.Rat).SetUint64(uint64(n)), true
+	case uint64:
+		return new(big.Rat).SetUint64(n), true
+	case float32:
+		return ratFromFloat(float64(n))
+	case float64:
+		return ratFromFloat(n)
+	case *big.Int:
+		return new(big.Rat).SetInt(n), true
+	case *big.Float:
+		if n.IsInf() {
+			return nil, false
+		}
+		r, _ := n.Rat(nil)
+		return r, true
+	case Decimal:
+		if abs32(n.scale) > maxDecimalScale {
+			return nil, false
+		}
+		return n.Rat(), true
+	}
+	return nil, false
+}
+
+func ratFromFloat(f float64) (*big.Rat, bool) {
+	r := new(big.Rat)
+	if r.SetFloat64(f) == nil {
+		return nil, false
+	}
+	return r, true
+}
@@ -0,0 +1,92 @@
+package anyvalue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClone(t *testing.T) {
+	config, err := LoadConfigYaml("./config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := config.Clone()
+	c.Set("redis.addr", "changed").Set("servers[]", "x")
+	c.Del("mysql")
+	if config.Get("redis.addr").AsStr() != "127.0.0.1:6379" || config.Has("servers") || !config.Has("mysql") {
+		t.Fatal("clone shares data with the original")
+	}
+
+	miss := config.Get("nope").Clone()
+	if miss.Err() == nil {
+		t.Fatal("clone of a missing value is not missing")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	yml, err := LoadConfigYaml("./config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	js, err := LoadConfigJson("./config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	yml.Del("gin")
+	yml.Set("redis.password", "")
+
+	if !yml.Equal(js) {
+		t.Fatal("yaml and json documents differ")
+	}
+
+	mp, err := yml.EncodeMsgPack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromMsgPack, err := NewFromMsgPack(mp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fromMsgPack.Equal(js) {
+		t.Fatal("msgpack and json documents differ")
+	}
+
+	if NewFromInf(int8(1)).Equal(NewFromInf(1.5)) || !NewFromInf(uint16(3)).Equal(NewFromInf(3.0)) {
+		t.Fatal("numeric comparison failed")
+	}
+	a, b := 0.1, 0.2
+	if NewFromInf(a + b).Equal(NewFromInf(0.3)) {
+		t.Fatal("expected float mismatch without tolerance")
+	}
+	if !NewFromInf(a+b).Equal(NewFromInf(0.3), FloatTolerance(1e-9)) {
+		t.Fatal("tolerance ignored")
+	}
+
+	null := NewFromInf(nil)
+	if !js.Get("a").Equal(js.Get("b")) || null.Equal(js.Get("a")) || !null.Equal(NewFromInf(nil)) {
+		t.Fatal("null and missing handling failed")
+	}
+}
+
+func TestEqualTime(t *testing.T) {
+	when := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	a := New().Set("when", when)
+	mp, err := a.EncodeMsgPack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := NewFromMsgPack(mp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !a.Clone().Equal(a) || !back.Equal(a) {
+		t.Fatal("identical timestamps compare unequal")
+	}
+	if n := len(Diff(a, back).AsArray()); n != 0 || len(DiffReport(a, back)) != 0 {
+		t.Fatalf("Diff found %d changes", n)
+	}
+	if a.Equal(New().Set("when", when.Add(time.Second))) {
+		t.Fatal("different timestamps compare equal")
+	}
+}
@@ -0,0 +1,88 @@
+package anyvalue
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by accessors, Decode and mutations match one of these
+// with errors.Is
+var (
+	// ErrNotFound means the path addresses nothing
+	ErrNotFound = errors.New("not found")
+	// ErrTypeMismatch means the value is not of the kind the operation
+	// needs, including strings that do not parse as the requested type and
+	// fractions where an integer is needed
+	ErrTypeMismatch = errors.New("type mismatch")
+	// ErrOverflow means a number is out of the range of the requested type
+	// or cannot be represented exactly in it
+	ErrOverflow = errors.New("overflow")
+	// ErrInvalidPath means a path or a pointer is malformed, or cannot be
+	// assigned
+	ErrInvalidPath = errors.New("invalid path")
+)
+
+// PathError describes an operation that failed on the value at Path:
+//
+//	var pe *anyvalue.PathError
+//	if errors.As(err, &pe) && errors.Is(err, anyvalue.ErrTypeMismatch) {
+//		log.Printf("%s: want %s, found %s", pe.Path, pe.Expected, pe.Actual)
+//	}
+type PathError struct {
+	// Path is the path of the value from the root it was looked up from,
+	// empty for the root itself
+	Path string
+	// Expected is the kind the operation needs and Actual the kind it
+	// found, Missing when the path addresses nothing
+	Expected Kind
+	Actual   Kind
+	// Msg details the error, when empty it is derived from Err and the kinds
+	Msg string
+	// Err is ErrNotFound, ErrTypeMismatch, ErrOverflow or ErrInvalidPath,
+	// or an error matching one of them such as a *LookupError
+	Err error
+}
+
+func (e *PathError) Error() string {
+	msg := e.Msg
+	if msg == "" {
+		if le, ok := e.Err.(*LookupError); ok {
+			// already cites the path
+			return le.Error()
+		}
+		if e.Err == ErrTypeMismatch {
+			msg = fmt.Sprintf("expected %s, got %s", e.Expected, e.Actual)
+		} else {
+			msg = e.Err.Error()
+		}
+	}
+	if e.Path == "" {
+		return msg
+	}
+	return e.Path + ": " + msg
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// mismatch reports that the value is not of the `expected` kind, or that
+// it is missing
+func (j *AnyValue) mismatch(expected Kind) error {
+	if j.miss != nil {
+		return &PathError{Path: j.path.String(), Expected: expected, Actual: Missing, Err: j.miss}
+	}
+	return &PathError{Path: j.path.String(), Expected: expected, Actual: j.Kind(), Err: ErrTypeMismatch}
+}
+
+// invalid reports a value of the right kind whose content does not parse
+// as the `expected` kind, such as a malformed duration string
+func (j *AnyValue) invalid(expected Kind, format string, args ...interface{}) error {
+	return &PathError{
+		Path:     j.path.String(),
+		Expected: expected,
+		Actual:   j.Kind(),
+		Msg:      fmt.Sprintf(format, args...),
+		Err:      ErrTypeMismatch,
+	}
+}
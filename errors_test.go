@@ -0,0 +1,65 @@
+package anyvalue
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPathErrors(t *testing.T) {
+	doc, err := NewFromJson([]byte(`{"redis":{"addr":"127.0.0.1:6379","max_conn":100000,"hosts":["a",1]},"ratio":1.5}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = doc.Get("redis.addr").Int()
+	var pe *PathError
+	if !errors.As(err, &pe) || !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("err = %#v", err)
+	}
+	if pe.Path != "redis.addr" || pe.Expected != Number || pe.Actual != String || err.Error() != "redis.addr: expected number, got string" {
+		t.Fatalf("err = %+v", pe)
+	}
+
+	_, err = doc.Get("redis.max_conn").Uint16()
+	if !errors.Is(err, ErrOverflow) || !errors.As(err, &pe) || pe.Path != "redis.max_conn" {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := doc.Get("ratio").Int(); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("err = %v", err)
+	}
+
+	_, err = doc.Get("redis.password").Str()
+	var le *LookupError
+	if !errors.Is(err, ErrNotFound) || !errors.As(err, &pe) || pe.Actual != Missing || !errors.As(err, &le) || le.At != "redis.password" {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := doc.Get("redis..addr").Map(); !errors.Is(err, ErrInvalidPath) || errors.Is(err, ErrNotFound) {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := doc.Get("redis.hosts").StrArr(); !errors.As(err, &pe) || pe.Path != "redis.hosts[1]" {
+		t.Fatalf("err = %v", err)
+	}
+
+	if err := doc.TrySet("redis.hosts.first", "x"); !errors.Is(err, ErrTypeMismatch) || !errors.As(err, &pe) || pe.Path != "redis.hosts.first" {
+		t.Fatalf("err = %v", err)
+	}
+	if err := doc.TrySet("a[", 1); !errors.Is(err, ErrInvalidPath) {
+		t.Fatalf("err = %v", err)
+	}
+
+	var redis struct {
+		MaxConn uint16 `json:"max_conn"`
+	}
+	err = doc.Get("redis").Decode(&redis)
+	if !errors.Is(err, ErrOverflow) || !errors.As(err, &pe) || pe.Path != "redis.max_conn" {
+		t.Fatalf("err = %v", err)
+	}
+	if err := doc.Get("missing").Decode(&redis); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("err = %v", err)
+	}
+
+	patch, _ := NewFromJson([]byte(`[{"op":"remove","path":"/nope"}]`))
+	if err := doc.ApplyPatch(patch); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,300 @@
+package anyvalue
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	jsonNumberType    = reflect.TypeOf(json.Number(""))
+	anyValueType      = reflect.TypeOf(&AnyValue{})
+	bigIntType        = reflect.TypeOf(&big.Int{})
+	bigFloatType      = reflect.TypeOf(&big.Float{})
+	decimalType       = reflect.TypeOf(Decimal{})
+)
+
+// FromValue builds an AnyValue from any Go value by reflection, producing
+// the same map[string]interface{} / []interface{} tree the decoders do, so
+// Get, Set and the encoders work on it:
+//
+//   - structs become maps keyed by their `json` tags (or `yaml` tags, or
+//     field names), honoring `omitempty` and `-`; embedded structs and
+//     `,squash`/`,inline` fields are flattened
+//   - maps become maps, with keys converted to strings
+//   - slices and arrays become arrays, except []byte which is kept
+//   - pointers and interfaces are followed, nil becomes null
+//   - values implementing encoding.TextMarshaler become strings, except
+//     time.Time, *big.Int and *big.Float which are kept as is
+//   - a time.Duration becomes a string such as "1m30s", see Set
+//
+// The result does not share memory with `v`.
+func FromValue(v interface{}) (*AnyValue, error) {
+	w := &fromWalker{visiting: map[visit]bool{}}
+	data, err := w.value(nil, reflect.ValueOf(v))
+	if err != nil {
+		return nil, err
+	}
+	return &AnyValue{data: data}, nil
+}
+
+// fromWalker converts a Go value, tracking the pointers, maps and slices
+// being converted to report cycles instead of recursing forever
+type fromWalker struct {
+	visiting map[visit]bool
+}
+
+// visit identifies a pointer, map or slice, slices of different lengths
+// sharing an array are different values
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// enter marks `v` as being converted and fails when it already is. The
+// caller calls the returned function once done with `v`.
+func (w *fromWalker) enter(p Path, v reflect.Value) (func(), error) {
+	key := visit{ptr: v.Pointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		key.len = v.Len()
+	}
+	if w.visiting[key] {
+		return nil, fromError(p, Unknown, "cycle through %s", v.Type())
+	}
+	w.visiting[key] = true
+	return func() { delete(w.visiting, key) }, nil
+}
+
+func (w *fromWalker) value(p Path, v reflect.Value) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	t := v.Type()
+	switch t {
+	case anyValueType:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return deepCopy(v.Interface().(*AnyValue).data), nil
+	case timeType, jsonNumberType, decimalType:
+		return v.Interface(), nil
+	case durationType:
+		return v.Interface().(time.Duration).String(), nil
+	case bigIntType, bigFloatType:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return deepCopy(v.Interface()), nil
+	}
+	nilable := t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface
+	if t.Implements(textMarshalerType) && !(nilable && v.IsNil()) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, fromError(p, String, "%v", err)
+		}
+		return string(text), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return w.value(p, v.Elem())
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil, nil
+		}
+		leave, err := w.enter(p, v)
+		if err != nil {
+			return nil, err
+		}
+		defer leave()
+		return w.value(p, v.Elem())
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int:
+		return int(v.Int()), nil
+	case reflect.Int8:
+		return int8(v.Int()), nil
+	case reflect.Int16:
+		return int16(v.Int()), nil
+	case reflect.Int32:
+		return int32(v.Int()), nil
+	case reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint:
+		return uint(v.Uint()), nil
+	case reflect.Uint8:
+		return uint8(v.Uint()), nil
+	case reflect.Uint16:
+		return uint16(v.Uint()), nil
+	case reflect.Uint32:
+		return uint32(v.Uint()), nil
+	case reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), nil
+	case reflect.Float32:
+		return float32(v.Float()), nil
+	case reflect.Float64:
+		return v.Float(), nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			return append([]byte(nil), v.Bytes()...), nil
+		}
+		leave, err := w.enter(p, v)
+		if err != nil {
+			return nil, err
+		}
+		defer leave()
+		return w.list(p, v)
+	case reflect.Array:
+		return w.list(p, v)
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		leave, err := w.enter(p, v)
+		if err != nil {
+			return nil, err
+		}
+		defer leave()
+		return w.mapValue(p, v)
+	case reflect.Struct:
+		m := make(map[string]interface{})
+		if err := w.structValue(p, v, m); err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+	return nil, fromError(p, Unknown, "unsupported type %s", t)
+}
+
+// fromError reports a Go value FromValue cannot convert
+func fromError(p Path, expected Kind, format string, args ...interface{}) error {
+	return &PathError{
+		Path:     p.String(),
+		Expected: expected,
+		Actual:   Unknown,
+		Msg:      fmt.Sprintf(format, args...),
+		Err:      ErrTypeMismatch,
+	}
+}
+
+func (w *fromWalker) list(p Path, v reflect.Value) (interface{}, error) {
+	a := make([]interface{}, v.Len())
+	for i := range a {
+		ep := append(append(Path{}, p...), PathSegment{Kind: SegmentIndex, Index: i})
+		e, err := w.value(ep, v.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		a[i] = e
+	}
+	return a, nil
+}
+
+func (w *fromWalker) mapValue(p Path, v reflect.Value) (interface{}, error) {
+	m := make(map[string]interface{}, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		k, err := mapKeyString(p, iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		ep := append(append(Path{}, p...), PathSegment{Kind: SegmentKey, Key: k})
+		e, err := w.value(ep, iter.Value())
+		if err != nil {
+			return nil, err
+		}
+		m[k] = e
+	}
+	return m, nil
+}
+
+// mapKeyString converts a map key the way encoding/json does
+func mapKeyString(p Path, k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if k.Type().Implements(textMarshalerType) {
+		text, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return "", fromError(p, String, "%v", err)
+		}
+		return string(text), nil
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	case reflect.Interface:
+		if !k.IsNil() {
+			return mapKeyString(p, k.Elem())
+		}
+	}
+	return "", fromError(p, String, "unsupported map key type %s", k.Type())
+}
+
+func (w *fromWalker) structValue(p Path, v reflect.Value, m map[string]interface{}) error {
+	for _, f := range structFields(v.Type()) {
+		fv, ok := fieldValue(v, f.index)
+		if !ok || f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		ep := append(append(Path{}, p...), PathSegment{Kind: SegmentKey, Key: f.name})
+		e, err := w.value(ep, fv)
+		if err != nil {
+			return err
+		}
+		m[f.name] = e
+	}
+	return nil
+}
+
+// fieldValue returns the nested field, or false when an embedded pointer on
+// the way is nil
+func fieldValue(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return v, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// isEmptyValue tells whether `omitempty` drops the value, as encoding/json
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package anyvalue
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+type port uint16
+
+type Meta struct {
+	Region string `json:"region"`
+}
+
+type server struct {
+	Name    string            `json:"name"`
+	Port    port              `json:"port"`
+	Addr    net.IP            `json:"addr,omitempty"`
+	Tags    []string          `json:"tags,omitempty"`
+	Secret  string            `json:"-"`
+	Labels  map[int]string    `json:"labels,omitempty"`
+	Started time.Time         `json:"started"`
+	Extra   map[string]string `yaml:"extra"`
+	Weight  float64
+	*Meta
+}
+
+func TestFromValue(t *testing.T) {
+	started := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := &server{
+		Name:    "a",
+		Port:    8081,
+		Addr:    net.IPv4(10, 0, 0, 1),
+		Secret:  "x",
+		Labels:  map[int]string{1: "one"},
+		Started: started,
+		Meta:    &Meta{Region: "eu"},
+	}
+
+	av, err := FromValue(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if av.Get("name").AsStr() != "a" || av.Get("port").AsInt() != 8081 || av.Get("addr").AsStr() != "10.0.0.1" {
+		out, _ := av.EncodeJson()
+		t.Fatalf("av = %s", out)
+	}
+	if av.Has("tags") || av.Has("Secret") || av.Get("labels.1").AsStr() != "one" {
+		t.Fatal("tags not honored")
+	}
+	if !av.Has("extra") || av.Get("extra").Interface() != nil || !av.Has("Weight") {
+		t.Fatal("empty fields dropped")
+	}
+	if av.Get("region").AsStr() != "eu" || av.Get("started").Interface() != started {
+		t.Fatal("embedded or time fields lost")
+	}
+
+	av.Set("tags[]", "new")
+	if s.Tags != nil {
+		t.Fatal("FromValue result shares memory with the source")
+	}
+
+	var back server
+	if err := av.Decode(&back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Name != s.Name || back.Port != s.Port || !back.Addr.Equal(s.Addr) || back.Region != "eu" {
+		t.Fatalf("back = %+v", back)
+	}
+
+	if _, err := FromValue(map[string]interface{}{"f": func() {}}); err == nil {
+		t.Fatal("expected error for func value")
+	}
+	if av, err := FromValue(nil); err != nil || av.Interface() != nil {
+		t.Fatal("nil not handled")
+	}
+}
+
+type node struct {
+	Name string `json:"name"`
+	Next *node  `json:"next"`
+}
+
+func TestFromValueCycle(t *testing.T) {
+	n := &node{Name: "a"}
+	n.Next = &node{Name: "b", Next: n}
+	_, err := FromValue(n)
+	var pe *PathError
+	if !errors.As(err, &pe) || err.Error() != "next.next: cycle through *anyvalue.node" {
+		t.Fatalf("err = %v", err)
+	}
+
+	// shared but acyclic values are converted twice
+	leaf := &node{Name: "leaf"}
+	v, err := FromValue([]*node{leaf, leaf})
+	if err != nil || v.Get("[1].name").AsStr() != "leaf" {
+		t.Fatalf("FromValue = %v, %v", v, err)
+	}
+
+	m := map[string]interface{}{}
+	m["self"] = m
+	if _, err := FromValue(m); err == nil {
+		t.Fatal("expected a cycle error for a map")
+	}
+}
@@ -1,8 +1,10 @@
 module github.com/polevpn/anyvalue
 
-go 1.14
+go 1.18
 
 require (
 	github.com/vmihailenco/msgpack/v5 v5.0.0
 	gopkg.in/yaml.v2 v2.3.0
 )
+
+require github.com/vmihailenco/tagparser v0.1.2 // indirect
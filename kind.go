@@ -0,0 +1,192 @@
+package anyvalue
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is the type of a value, whatever Go type the decoder produced for it
+type Kind int
+
+const (
+	// Null is an explicit null
+	Null Kind = iota
+	Bool
+	// Number covers every Go numeric type, json.Number, *big.Int,
+	// *big.Float and Decimal
+	Number
+	String
+	Array
+	Object
+	// Binary is a []byte, such as a msgpack bin value
+	Binary
+	// Time is a time.Time, such as a msgpack timestamp
+	Time
+	// Missing is the kind of a value returned by a lookup that found nothing
+	Missing
+	// Unknown is the kind of Go values with no equivalent in the supported
+	// formats, such as funcs or channels
+	Unknown
+)
+
+var kindNames = []string{
+	Null:    "null",
+	Bool:    "bool",
+	Number:  "number",
+	String:  "string",
+	Array:   "array",
+	Object:  "object",
+	Binary:  "binary",
+	Time:    "time",
+	Missing: "missing",
+	Unknown: "unknown",
+}
+
+func (k Kind) String() string {
+	if k >= 0 && int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Kind returns the kind of the value
+func (j *AnyValue) Kind() Kind {
+	if j.miss != nil {
+		return Missing
+	}
+	return kindOf(j.data)
+}
+
+func kindOf(v interface{}) Kind {
+	switch v.(type) {
+	case nil:
+		return Null
+	case bool:
+		return Bool
+	case json.Number, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return Number
+	case *big.Int, *big.Float, Decimal:
+		return Number
+	case string:
+		return String
+	case []interface{}:
+		return Array
+	case map[string]interface{}, map[interface{}]interface{}:
+		return Object
+	case []byte:
+		return Binary
+	case time.Time:
+		return Time
+	}
+
+	// named types that were put into the tree as is
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return Bool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return Number
+	case reflect.String:
+		return String
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return Binary
+		}
+		return Array
+	case reflect.Map, reflect.Struct:
+		return Object
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return Null
+		}
+		return kindOf(rv.Elem().Interface())
+	}
+	return Unknown
+}
+
+// kindOfType returns the kind of the values a Go type holds, Unknown for
+// interfaces and types with no equivalent
+func kindOfType(t reflect.Type) Kind {
+	switch t {
+	case timeType:
+		return Time
+	case bigIntType, bigFloatType, decimalType, jsonNumberType:
+		return Number
+	case durationType:
+		return String
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return Bool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return Number
+	case reflect.String:
+		return String
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Binary
+		}
+		return Array
+	case reflect.Map:
+		return Object
+	case reflect.Struct:
+		if t == bigIntType.Elem() || t == bigFloatType.Elem() {
+			return Number
+		}
+		return Object
+	case reflect.Ptr:
+		return kindOfType(t.Elem())
+	}
+	return Unknown
+}
+
+// IsNull reports whether the value is an explicit null, missing values are
+// not null
+func (j *AnyValue) IsNull() bool {
+	return j.Kind() == Null
+}
+
+// IsMissing reports whether the value comes from a lookup that found nothing
+func (j *AnyValue) IsMissing() bool {
+	return j.miss != nil
+}
+
+// IsInteger reports whether the value is a number written without fraction
+// or exponent, such as an int from YAML or the json.Number "10"
+func (j *AnyValue) IsInteger() bool {
+	switch n := j.data.(type) {
+	case json.Number:
+		return j.miss == nil && !strings.ContainsAny(string(n), ".eE")
+	case Decimal:
+		return j.miss == nil && n.scale <= 0
+	case float32, float64, *big.Float:
+		return false
+	}
+	return j.Kind() == Number
+}
+
+// IsFloat reports whether the value is a floating point number, such as a
+// float64 from YAML or the json.Number "1.5"
+func (j *AnyValue) IsFloat() bool {
+	switch n := j.data.(type) {
+	case json.Number:
+		return j.miss == nil && strings.ContainsAny(string(n), ".eE")
+	case Decimal:
+		return j.miss == nil && n.scale > 0
+	case float32, float64, *big.Float:
+		return j.miss == nil
+	}
+	if j.Kind() != Number {
+		return false
+	}
+	k := reflect.ValueOf(j.data).Kind()
+	return k == reflect.Float32 || k == reflect.Float64
+}
@@ -0,0 +1,56 @@
+package anyvalue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKind(t *testing.T) {
+	js, err := NewFromJson([]byte(`{"nul":null,"b":true,"i":10,"f":1.5,"e":1e3,"s":"x","a":[],"o":{}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	yml, err := NewFromYaml([]byte("nul: ~\nb: true\ni: 10\nf: 1.5\ne: 1.0e+3\ns: x\na: []\no: {}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mp, err := yml.EncodeMsgPack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := NewFromMsgPack(mp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]Kind{
+		"nul": Null, "b": Bool, "i": Number, "f": Number, "e": Number,
+		"s": String, "a": Array, "o": Object, "nope": Missing,
+	}
+	for _, doc := range []*AnyValue{js, yml, msg} {
+		for path, kind := range want {
+			if k := doc.Get(path).Kind(); k != kind {
+				t.Fatalf("%s: Kind() = %v, want %v", path, k, kind)
+			}
+		}
+		if !doc.Get("i").IsInteger() || doc.Get("i").IsFloat() {
+			t.Fatal("i is not an integer")
+		}
+		if !doc.Get("f").IsFloat() || doc.Get("f").IsInteger() || !doc.Get("f").IsNumber() {
+			t.Fatal("f is not a float")
+		}
+		if !doc.Get("nul").IsNull() || doc.Get("nul").IsMissing() {
+			t.Fatal("explicit null misreported")
+		}
+		if doc.Get("nope").IsNull() || !doc.Get("nope").IsMissing() {
+			t.Fatal("missing value misreported")
+		}
+	}
+
+	if NewFromInf([]byte("x")).Kind() != Binary || NewFromInf(time.Now()).Kind() != Time {
+		t.Fatal("binary or time misreported")
+	}
+	if NewFromInf(make(chan int)).Kind() != Unknown || Kind(42).String() != "Kind(42)" {
+		t.Fatal("unknown kind misreported")
+	}
+}
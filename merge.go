@@ -0,0 +1,135 @@
+package anyvalue
+
+// ArrayStrategy tells Merge how to combine an array with another array
+type ArrayStrategy int
+
+const (
+	// ArrayReplace replaces the array with the other one
+	ArrayReplace ArrayStrategy = iota
+	// ArrayAppend appends the elements of the other array
+	ArrayAppend
+	// ArrayMergeByKey merges map elements that have the same value for a key
+	// field and appends the other elements, see MergeArraysByKey
+	ArrayMergeByKey
+)
+
+type mergeOptions struct {
+	nullDeletes bool
+	arrays      ArrayStrategy
+	key         string
+}
+
+// MergeOption configures Merge
+type MergeOption func(*mergeOptions)
+
+// MergePatch selects RFC 7386 JSON Merge Patch semantics: null values delete
+// the key and arrays are replaced
+func MergePatch() MergeOption {
+	return func(o *mergeOptions) {
+		o.nullDeletes = true
+		o.arrays = ArrayReplace
+	}
+}
+
+// NullDeletes makes null values delete the key instead of storing null
+func NullDeletes() MergeOption {
+	return func(o *mergeOptions) {
+		o.nullDeletes = true
+	}
+}
+
+// AppendArrays appends the elements of the other arrays
+func AppendArrays() MergeOption {
+	return func(o *mergeOptions) {
+		o.arrays = ArrayAppend
+	}
+}
+
+// MergeArraysByKey merges array elements that are maps with the same value
+// for `field`, such as the "name" of a list of servers. Elements without a
+// match are appended.
+func MergeArraysByKey(field string) MergeOption {
+	return func(o *mergeOptions) {
+		o.arrays = ArrayMergeByKey
+		o.key = field
+	}
+}
+
+// Merge deep merges `other` into the value and returns it. Maps are merged
+// key by key, arrays according to the ArrayStrategy (replaced by default)
+// and every other value of `other` replaces the existing one. Values are
+// copied from `other`, so later changes to one do not affect the other. A
+// missing `other`, such as the result of Get on an absent key, changes
+// nothing.
+//
+// useful to layer overrides on top of defaults:
+//
+//	config.Merge(env, anyvalue.MergeArraysByKey("name"))
+//	config.Merge(patch, anyvalue.MergePatch())
+func (j *AnyValue) Merge(other *AnyValue, opts ...MergeOption) *AnyValue {
+	if other.miss != nil {
+		return j
+	}
+	o := &mergeOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	j.data = mergeValue(j.data, other.data, o)
+	j.miss = nil
+	return j
+}
+
+func mergeValue(dst, src interface{}, o *mergeOptions) interface{} {
+	switch s := src.(type) {
+	case map[string]interface{}:
+		d, ok := dst.(map[string]interface{})
+		if !ok {
+			d = make(map[string]interface{}, len(s))
+		}
+		for k, v := range s {
+			if v == nil && o.nullDeletes {
+				delete(d, k)
+				continue
+			}
+			d[k] = mergeValue(d[k], v, o)
+		}
+		return d
+	case []interface{}:
+		d, ok := dst.([]interface{})
+		if !ok {
+			break
+		}
+		switch o.arrays {
+		case ArrayAppend:
+			return append(d, deepCopy(s).([]interface{})...)
+		case ArrayMergeByKey:
+			return mergeByKey(d, s, o)
+		}
+	}
+	return deepCopy(src)
+}
+
+func mergeByKey(dst, src []interface{}, o *mergeOptions) []interface{} {
+	for _, v := range src {
+		m, ok := v.(map[string]interface{})
+		key, hasKey := m[o.key]
+		if !ok || !hasKey {
+			dst = append(dst, deepCopy(v))
+			continue
+		}
+		matched := false
+		for i, e := range dst {
+			if em, ok := e.(map[string]interface{}); ok {
+				if ek, ok := em[o.key]; ok && deepEqual(ek, key) {
+					dst[i] = mergeValue(em, m, o)
+					matched = true
+					break
+				}
+			}
+		}
+		if !matched {
+			dst = append(dst, deepCopy(v))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,88 @@
+package anyvalue
+
+import (
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	config, err := LoadConfigYaml("./config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := NewFromJson([]byte(`{"redis":{"addr":"10.0.0.1:6379","password":null},"gin":{"mode":"release"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config.Merge(env)
+	if config.Get("redis.addr").AsStr() != "10.0.0.1:6379" || config.Get("redis.max_conn").AsInt() != 100 {
+		t.Fatal("deep merge failed")
+	}
+	if config.Get("gin.mode").AsStr() != "release" || config.Get("gin.log").AsStr() != "console" {
+		t.Fatal("deep merge failed")
+	}
+	if !config.Has("redis.password") {
+		t.Fatal("null deleted without MergePatch")
+	}
+
+	env.Set("redis.addr", "changed")
+	if config.Get("redis.addr").AsStr() != "10.0.0.1:6379" {
+		t.Fatal("merged value aliases the source")
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	// example from RFC 7386 section 3
+	target, _ := NewFromJson([]byte(`{"title":"Goodbye!","author":{"givenName":"John","familyName":"Doe"},"tags":["example","sample"],"content":"This will be unchanged"}`))
+	patch, _ := NewFromJson([]byte(`{"title":"Hello!","phoneNumber":"+01-234-567-8900","author":{"familyName":null},"tags":["example"]}`))
+	want, _ := NewFromJson([]byte(`{"title":"Hello!","author":{"givenName":"John"},"tags":["example"],"content":"This will be unchanged","phoneNumber":"+01-234-567-8900"}`))
+
+	target.Merge(patch, MergePatch())
+	if !deepEqual(target.data, want.data) {
+		out, _ := target.EncodeJson()
+		t.Fatalf("merged = %s", out)
+	}
+
+	target.Merge(NewFromInf("scalar"), MergePatch())
+	if target.AsStr() != "scalar" {
+		t.Fatal("non-object patch did not replace the target")
+	}
+}
+
+func TestMergeArrays(t *testing.T) {
+	base, _ := NewFromYaml([]byte("servers:\n  - {name: a, port: 1}\n  - {name: b, port: 2}\ntags: [x]\n"))
+	over, _ := NewFromYaml([]byte("servers:\n  - {name: b, port: 3}\n  - {name: c, port: 4}\ntags: [y]\n"))
+
+	merged := NewFromInf(deepCopy(base.data)).Merge(over, AppendArrays())
+	if len(merged.Get("servers").AsArray()) != 4 || len(merged.Get("tags").AsArray()) != 2 {
+		t.Fatal("append failed")
+	}
+
+	merged = NewFromInf(deepCopy(base.data)).Merge(over, MergeArraysByKey("name"))
+	if len(merged.Get("servers").AsArray()) != 3 || merged.Get("servers[1].port").AsInt() != 3 || merged.Get("servers[2].name").AsStr() != "c" {
+		out, _ := merged.EncodeJson()
+		t.Fatalf("merge by key = %s", out)
+	}
+
+	merged = NewFromInf(deepCopy(base.data)).Merge(over)
+	if len(merged.Get("servers").AsArray()) != 2 || merged.Get("servers[0].name").AsStr() != "b" {
+		t.Fatal("replace failed")
+	}
+}
+
+func TestMergeMissing(t *testing.T) {
+	config, err := LoadConfigYaml("./config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := New().Set("other", 1)
+	config.Merge(env.Get("overrides"))
+	if config.Get("redis.addr").AsStr() != "127.0.0.1:6379" {
+		t.Fatal("merging a missing value changed the document")
+	}
+
+	config.Merge(NewFromInf(nil))
+	if config.Interface() != nil {
+		t.Fatal("an explicit null did not replace the document")
+	}
+}
@@ -0,0 +1,143 @@
+package anyvalue
+
+import (
+	"bytes"
+	"reflect"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// MsgPackDecimalExt is the msgpack extension type ('D') of numbers that do
+// not fit an int64, an uint64 or a float64 without losing precision. The
+// payload is the number in JSON notation, it decodes to a Decimal.
+//
+// The msgpack encoders and decoders of this package handle the extension
+// themselves, it is not registered with the msgpack package so other users
+// of that extension type in the same program are not affected. Programs
+// decoding such documents with msgpack directly can call
+// RegisterMsgPackDecimal.
+const MsgPackDecimalExt int8 = 68
+
+// RegisterMsgPackDecimal registers MsgPackDecimalExt with the msgpack
+// package, for every encoder and decoder of the program
+func RegisterMsgPackDecimal() {
+	msgpack.RegisterExtEncoder(MsgPackDecimalExt, Decimal{}, func(_ *msgpack.Encoder, v reflect.Value) ([]byte, error) {
+		return []byte(v.Interface().(Decimal).String()), nil
+	})
+	msgpack.RegisterExtDecoder(MsgPackDecimalExt, Decimal{}, func(dec *msgpack.Decoder, v reflect.Value, n int) error {
+		b, err := readExtData(dec, n)
+		if err != nil {
+			return err
+		}
+		d, err := ParseDecimal(string(b))
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(d))
+		return nil
+	})
+}
+
+// msgpackDecimal writes a Decimal as a MsgPackDecimalExt extension
+type msgpackDecimal Decimal
+
+func (d msgpackDecimal) EncodeMsgpack(enc *msgpack.Encoder) error {
+	text := Decimal(d).String()
+	if err := enc.EncodeExtHeader(MsgPackDecimalExt, len(text)); err != nil {
+		return err
+	}
+	_, err := enc.Writer().Write([]byte(text))
+	return err
+}
+
+// decodeMsgPack decodes the next value like msgpack.Decoder.DecodeInterface
+// does, except that MsgPackDecimalExt extensions become Decimal values. Other
+// extensions are decoded by the types registered with msgpack.
+func decodeMsgPack(d *msgpack.Decoder) (interface{}, error) {
+	c, err := d.PeekCode()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case msgpcode.IsFixedMap(c) || c == msgpcode.Map16 || c == msgpcode.Map32:
+		n, err := d.DecodeMapLen()
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[string]interface{})
+		for i := 0; i < n; i++ {
+			k, err := d.DecodeString()
+			if err != nil {
+				return nil, err
+			}
+			if m[k], err = decodeMsgPack(d); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	case msgpcode.IsFixedArray(c) || c == msgpcode.Array16 || c == msgpcode.Array32:
+		n, err := d.DecodeArrayLen()
+		if err != nil {
+			return nil, err
+		}
+		var a []interface{}
+		for i := 0; i < n; i++ {
+			v, err := decodeMsgPack(d)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, v)
+		}
+		if a == nil {
+			a = []interface{}{}
+		}
+		return a, nil
+	case msgpcode.IsExt(c):
+		id, n, err := d.DecodeExtHeader()
+		if err != nil {
+			return nil, err
+		}
+		b, err := readExtData(d, n)
+		if err != nil {
+			return nil, err
+		}
+		if id == MsgPackDecimalExt {
+			return ParseDecimal(string(b))
+		}
+		return decodeRegisteredExt(id, b)
+	}
+	return d.DecodeInterface()
+}
+
+// readExtData reads the `n` bytes of an extension payload, growing the
+// buffer as data arrives rather than trusting the length up front
+func readExtData(d *msgpack.Decoder, n int) ([]byte, error) {
+	var buf bytes.Buffer
+	chunk := make([]byte, 4096)
+	for n > 0 {
+		k := len(chunk)
+		if n < k {
+			k = n
+		}
+		if err := d.ReadFull(chunk[:k]); err != nil {
+			return nil, err
+		}
+		buf.Write(chunk[:k])
+		n -= k
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeRegisteredExt decodes an extension with the decoder registered with
+// msgpack for its type, such as the one of time.Time
+func decodeRegisteredExt(id int8, data []byte) (interface{}, error) {
+	var buf bytes.Buffer
+	if err := msgpack.NewEncoder(&buf).EncodeExtHeader(id, len(data)); err != nil {
+		return nil, err
+	}
+	buf.Write(data)
+	var v interface{}
+	err := msgpack.Unmarshal(buf.Bytes(), &v)
+	return v, err
+}
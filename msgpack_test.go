@@ -0,0 +1,49 @@
+package anyvalue
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestMsgPackExtensions(t *testing.T) {
+	price, _ := ParseDecimal("19.990000000000000000001")
+	when := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	mp, err := New().Set("price", price).Set("when", when).Set("list", []interface{}{price}).EncodeMsgPack()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := NewFromMsgPack(mp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, err := doc.Get("list[0]").Decimal(); err != nil || d.String() != price.String() {
+		t.Fatalf("list[0] = %v, %v", d, err)
+	}
+	if got, ok := doc.Get("when").Interface().(time.Time); !ok || !got.Equal(when) {
+		t.Fatalf("when = %v", doc.Get("when").Interface())
+	}
+
+	// the extension is not registered with msgpack
+	var out interface{}
+	if err := msgpack.Unmarshal(mp, &out); err == nil {
+		t.Fatal("expected plain msgpack to reject the decimal extension")
+	}
+
+	// an extension claiming 4GB of data in a few bytes
+	if _, err := NewFromMsgPack([]byte{0xc9, 0xff, 0xff, 0xff, 0xff, byte(MsgPackDecimalExt), '1'}); !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		t.Fatalf("err = %v", err)
+	}
+
+	RegisterMsgPackDecimal()
+	if err := msgpack.Unmarshal(mp, &out); err != nil {
+		t.Fatal(err)
+	}
+	if d, ok := out.(map[string]interface{})["price"].(Decimal); !ok || d.String() != price.String() {
+		t.Fatalf("price = %v", out)
+	}
+}
@@ -0,0 +1,119 @@
+package anyvalue
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// maxMustValue bounds the length of the value quoted by a Must panic
+const maxMustValue = 64
+
+// MustError is the panic value of MustGet and the Must accessors, it names
+// the path, the expected type and the value that was found:
+//
+//	anyvalue: redis.max_conn: expected uint16, got number 100000 (100000 overflows uint16)
+//	anyvalue: redis.password: expected string, value is missing
+type MustError struct {
+	Path string
+	// Type is the Go type that was requested
+	Type string
+	// Value is the value found at Path, missing when there is none
+	Value *AnyValue
+	// Err is the error of the accessor, a *PathError
+	Err error
+}
+
+func (e *MustError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "(root)"
+	}
+	if e.Value.IsMissing() {
+		return fmt.Sprintf("anyvalue: %s: expected %s, value is missing", path, e.Type)
+	}
+	got := renderValue(e.Value.data)
+	if len(got) > maxMustValue {
+		got = got[:maxMustValue] + "..."
+	}
+	msg := fmt.Sprintf("anyvalue: %s: expected %s, got %s %s", path, e.Type, e.Value.Kind(), got)
+	var pe *PathError
+	if errors.As(e.Err, &pe) && pe.Msg != "" {
+		msg += " (" + pe.Msg + ")"
+	}
+	return msg
+}
+
+func (e *MustError) Unwrap() error {
+	return e.Err
+}
+
+// must reads the value at `path` as a T or panics with a *MustError
+func must[T any](j *AnyValue, path string) T {
+	v := j.Get(path)
+	out, err := As[T](v)
+	if err != nil {
+		panic(&MustError{
+			Path:  v.Path(),
+			Type:  reflect.TypeOf((*T)(nil)).Elem().String(),
+			Value: v,
+			Err:   err,
+		})
+	}
+	return out
+}
+
+// MustStr returns the string at `path`, for required settings read at
+// startup. It panics with a *MustError when the value is missing or is not
+// a string.
+//
+//	addr := config.MustStr("redis.addr")
+func (j *AnyValue) MustStr(path string) string {
+	return must[string](j, path)
+}
+
+// MustBool returns the bool at `path` or panics, see MustStr
+func (j *AnyValue) MustBool(path string) bool {
+	return must[bool](j, path)
+}
+
+// MustInt returns the int at `path` or panics, see MustStr
+func (j *AnyValue) MustInt(path string) int {
+	return must[int](j, path)
+}
+
+// MustInt64 returns the int64 at `path` or panics, see MustStr
+func (j *AnyValue) MustInt64(path string) int64 {
+	return must[int64](j, path)
+}
+
+// MustUint64 returns the uint64 at `path` or panics, see MustStr
+func (j *AnyValue) MustUint64(path string) uint64 {
+	return must[uint64](j, path)
+}
+
+// MustFloat64 returns the float64 at `path` or panics, see MustStr
+func (j *AnyValue) MustFloat64(path string) float64 {
+	return must[float64](j, path)
+}
+
+// MustDuration returns the time.Duration at `path` or panics, see MustStr
+func (j *AnyValue) MustDuration(path string) time.Duration {
+	return must[time.Duration](j, path)
+}
+
+// MustMap returns the map at `path` or panics, see MustStr
+func (j *AnyValue) MustMap(path string) map[string]interface{} {
+	return must[map[string]interface{}](j, path)
+}
+
+// MustArray returns the array at `path` or panics, see MustStr
+func (j *AnyValue) MustArray(path string) []interface{} {
+	return must[[]interface{}](j, path)
+}
+
+// MustStrArr returns the array of strings at `path` or panics, see MustStr
+func (j *AnyValue) MustStrArr(path string) []string {
+	return must[[]string](j, path)
+}
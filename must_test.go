@@ -0,0 +1,54 @@
+package anyvalue
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func mustPanic(t *testing.T, f func()) *MustError {
+	t.Helper()
+	var me *MustError
+	func() {
+		defer func() {
+			err, _ := recover().(error)
+			if !errors.As(err, &me) {
+				t.Fatalf("recover() = %v", err)
+			}
+		}()
+		f()
+	}()
+	return me
+}
+
+func TestMust(t *testing.T) {
+	config, err := LoadConfigYaml("./config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Set("redis.timeout", "5s").Set("redis.max_conn", 100000)
+
+	if config.MustStr("redis.addr") != "127.0.0.1:6379" || config.MustInt("redis.db") != 0 || config.MustDuration("redis.timeout") != 5*time.Second {
+		t.Fatal("Must accessors")
+	}
+	if config.Get("redis").MustMap("")["addr"] != "127.0.0.1:6379" {
+		t.Fatal("MustMap")
+	}
+
+	me := mustPanic(t, func() { config.MustStr("redis.user") })
+	if me.Error() != "anyvalue: redis.user: expected string, value is missing" || !errors.Is(me, ErrNotFound) {
+		t.Fatalf("err = %v", me)
+	}
+	me = mustPanic(t, func() { config.MustInt("gin.mode") })
+	if me.Error() != `anyvalue: gin.mode: expected int, got string "debug"` || !errors.Is(me, ErrTypeMismatch) {
+		t.Fatalf("err = %v", me)
+	}
+	me = mustPanic(t, func() { MustGet[uint16](config, "redis.max_conn") })
+	if me.Error() != "anyvalue: redis.max_conn: expected uint16, got number 100000 (100000 overflows uint16)" {
+		t.Fatalf("err = %v", me)
+	}
+	me = mustPanic(t, func() { config.Get("mysql").MustBool("max_conn") })
+	if me.Path != "mysql.max_conn" || me.Type != "bool" {
+		t.Fatalf("err = %+v", me)
+	}
+}
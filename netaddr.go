@@ -0,0 +1,145 @@
+package anyvalue
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+)
+
+// addr returns the string an address accessor parses
+func (j *AnyValue) addr() (string, error) {
+	s, ok := j.data.(string)
+	if !ok {
+		return "", j.mismatch(String)
+	}
+	return s, nil
+}
+
+// IP reads an IPv4 or IPv6 address such as "10.0.0.1" or "fe80::1"
+func (j *AnyValue) IP() (net.IP, error) {
+	if ip, ok := j.data.(net.IP); ok && j.miss == nil {
+		return append(net.IP(nil), ip...), nil
+	}
+	s, err := j.addr()
+	if err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, j.invalid(String, "invalid IP address %q", s)
+	}
+	return ip, nil
+}
+
+// IPNet reads a network in CIDR notation such as "10.0.0.0/8", a bare
+// address is a network of that single address. Host bits are cleared,
+// "10.1.2.3/8" is 10.0.0.0/8.
+func (j *AnyValue) IPNet() (*net.IPNet, error) {
+	if n, ok := j.data.(*net.IPNet); ok && j.miss == nil {
+		return &net.IPNet{IP: append(net.IP(nil), n.IP...), Mask: append(net.IPMask(nil), n.Mask...)}, nil
+	}
+	s, err := j.addr()
+	if err != nil {
+		return nil, err
+	}
+	if ip := net.ParseIP(s); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, j.invalid(String, "invalid network %q", s)
+	}
+	return n, nil
+}
+
+// HostPort reads an endpoint such as "127.0.0.1:6379", "[::1]:80" or
+// ":8081", whose host is empty. The port must be a number.
+func (j *AnyValue) HostPort() (string, uint16, error) {
+	s, err := j.addr()
+	if err != nil {
+		return "", 0, err
+	}
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return "", 0, j.invalid(String, "invalid host:port %q", s)
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return "", 0, j.invalid(String, "invalid port in %q", s)
+	}
+	return host, uint16(p), nil
+}
+
+// URL reads an absolute URL such as "https://upstream:8443/api" or
+// "unix:///run/app.sock"
+func (j *AnyValue) URL() (*url.URL, error) {
+	s, err := j.addr()
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" {
+		return nil, j.invalid(String, "invalid URL %q", s)
+	}
+	return u, nil
+}
+
+// IPArr reads an array of IP addresses, the error cites the first invalid
+// element
+func (j *AnyValue) IPArr() ([]net.IP, error) {
+	elems, err := j.Elems()
+	if err != nil {
+		return nil, err
+	}
+	ips := make([]net.IP, len(elems))
+	for i, e := range elems {
+		if ips[i], err = e.IP(); err != nil {
+			return nil, err
+		}
+	}
+	return ips, nil
+}
+
+// IPNetArr reads an array of networks, such as a route list, the error
+// cites the first invalid element
+func (j *AnyValue) IPNetArr() ([]*net.IPNet, error) {
+	elems, err := j.Elems()
+	if err != nil {
+		return nil, err
+	}
+	nets := make([]*net.IPNet, len(elems))
+	for i, e := range elems {
+		if nets[i], err = e.IPNet(); err != nil {
+			return nil, err
+		}
+	}
+	return nets, nil
+}
+
+// AsIP guarantees the return of a `net.IP` (with optional default)
+func (j *AnyValue) AsIP(args ...net.IP) net.IP {
+	return orDefault(j.IP, args)
+}
+
+// AsIPNet guarantees the return of a `*net.IPNet` (with optional default)
+func (j *AnyValue) AsIPNet(args ...*net.IPNet) *net.IPNet {
+	return orDefault(j.IPNet, args)
+}
+
+// AsURL guarantees the return of a `*url.URL` (with optional default)
+func (j *AnyValue) AsURL(args ...*url.URL) *url.URL {
+	return orDefault(j.URL, args)
+}
+
+// AsIPArr guarantees the return of a `[]net.IP` (with optional default)
+func (j *AnyValue) AsIPArr(args ...[]net.IP) []net.IP {
+	return orDefault(j.IPArr, args)
+}
+
+// AsIPNetArr guarantees the return of a `[]*net.IPNet` (with optional default)
+func (j *AnyValue) AsIPNetArr(args ...[]*net.IPNet) []*net.IPNet {
+	return orDefault(j.IPNetArr, args)
+}
@@ -0,0 +1,74 @@
+package anyvalue
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestHostPort(t *testing.T) {
+	config, err := LoadConfigYaml("./config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if host, port, err := config.Get("listen").HostPort(); err != nil || host != "" || port != 8081 {
+		t.Fatalf("HostPort() = %q, %v, %v", host, port, err)
+	}
+	if host, port, err := config.Get("redis.addr").HostPort(); err != nil || host != "127.0.0.1" || port != 6379 {
+		t.Fatalf("HostPort() = %q, %v, %v", host, port, err)
+	}
+	if _, _, err := config.Get("gin.mode").HostPort(); err == nil || !strings.HasPrefix(err.Error(), "gin.mode: ") {
+		t.Fatalf("err = %v", err)
+	}
+	if _, _, err := config.Get("redis.max_conn").HostPort(); err == nil || err.Error() != "redis.max_conn: expected string, got number" {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestIPAndURL(t *testing.T) {
+	doc, err := NewFromYaml([]byte(`
+gateway: 10.8.0.1
+dns: ["1.1.1.1", "2606:4700::1111"]
+routes: [10.0.0.0/8, 192.168.1.7/24, 172.16.0.1, "fd00::/8"]
+bad_routes: [10.0.0.0/8, 300.0.0.0/8]
+upstream: https://vpn.example.com:8443/api
+relative: /api
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ip, err := doc.Get("gateway").IP(); err != nil || !ip.Equal(net.IPv4(10, 8, 0, 1)) {
+		t.Fatalf("IP() = %v, %v", ip, err)
+	}
+	if ips, err := doc.Get("dns").IPArr(); err != nil || len(ips) != 2 || ips[1].To4() != nil {
+		t.Fatalf("IPArr() = %v, %v", ips, err)
+	}
+	routes, err := doc.Get("routes").IPNetArr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if routes[1].String() != "192.168.1.0/24" || routes[2].String() != "172.16.0.1/32" || routes[3].String() != "fd00::/8" {
+		t.Fatalf("IPNetArr() = %v", routes)
+	}
+	if _, err := doc.Get("bad_routes").IPNetArr(); err == nil || err.Error() != `bad_routes[1]: invalid network "300.0.0.0/8"` {
+		t.Fatalf("err = %v", err)
+	}
+	if doc.Get("bad_routes").AsIPNetArr() != nil {
+		t.Fatal("AsIPNetArr() ignored the error")
+	}
+	if doc.Get("missing").AsIP(net.IPv4zero) == nil {
+		t.Fatal("default ignored")
+	}
+
+	if u, err := doc.Get("upstream").URL(); err != nil || u.Port() != "8443" {
+		t.Fatalf("URL() = %v, %v", u, err)
+	}
+	if _, err := doc.Get("relative").URL(); err == nil {
+		t.Fatal("expected error on a relative URL")
+	}
+	if doc.Get("missing").AsURL() != nil {
+		t.Fatal("AsURL() returned a value for a missing path")
+	}
+}
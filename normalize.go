@@ -0,0 +1,136 @@
+package anyvalue
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+type decodeOptions struct {
+	strictKeys bool
+	weak       bool
+	// binaryStrings turns strings that are not valid UTF-8 into []byte,
+	// the YAML decoder only produces them for !!binary values
+	binaryStrings bool
+}
+
+// DecodeOption configures decoding, either of a document by the NewFrom*
+// functions or of a value into Go types by Decode
+type DecodeOption func(*decodeOptions)
+
+// StrictKeys makes decoding fail on maps with non-string keys instead of
+// converting the keys to strings.
+//
+// Use it for YAML documents whose keys may be words such as `y`, `n`, `on`
+// or `off`: yaml.v2 follows YAML 1.1 and resolves them to bools, so without
+// StrictKeys `y: 2` is stored under the key "true" and Get("y") finds
+// nothing. Quoting such keys ("y": 2) keeps them as written.
+func StrictKeys() DecodeOption {
+	return func(o *decodeOptions) {
+		o.strictKeys = true
+	}
+}
+
+func newDecodeOptions(opts []DecodeOption) *decodeOptions {
+	o := &decodeOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// normalize converts every map of the tree into map[string]interface{}, the
+// representation JSON decoding produces, so that all formats can be read and
+// modified the same way. Maps and arrays are converted in place.
+func normalize(v interface{}, o *decodeOptions) (interface{}, error) {
+	return normalizeAt(v, nil, o)
+}
+
+func normalizeAt(v interface{}, p Path, o *decodeOptions) (interface{}, error) {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		for k, e := range c {
+			n, err := normalizeAt(e, append(p, PathSegment{Kind: SegmentKey, Key: k}), o)
+			if err != nil {
+				return nil, err
+			}
+			c[k] = n
+		}
+		return c, nil
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(c))
+		var converted []interface{}
+		for k, e := range c {
+			s, ok := k.(string)
+			if !ok {
+				if o.strictKeys {
+					return nil, &PathError{
+						Path:     p.String(),
+						Expected: String,
+						Actual:   kindOf(k),
+						Msg:      fmt.Sprintf("non-string key %v (%T)", k, k),
+						Err:      ErrTypeMismatch,
+					}
+				}
+				// stringified keys are added last so that a real string key
+				// wins on collision
+				converted = append(converted, k)
+				continue
+			}
+			n, err := normalizeAt(e, append(p, PathSegment{Kind: SegmentKey, Key: s}), o)
+			if err != nil {
+				return nil, err
+			}
+			m[s] = n
+		}
+		for _, k := range converted {
+			s := keyString(k)
+			if _, ok := m[s]; ok {
+				continue
+			}
+			n, err := normalizeAt(c[k], append(p, PathSegment{Kind: SegmentKey, Key: s}), o)
+			if err != nil {
+				return nil, err
+			}
+			m[s] = n
+		}
+		return m, nil
+	case []interface{}:
+		for i, e := range c {
+			n, err := normalizeAt(e, append(p, PathSegment{Kind: SegmentIndex, Index: i}), o)
+			if err != nil {
+				return nil, err
+			}
+			c[i] = n
+		}
+		return c, nil
+	case string:
+		if o.binaryStrings && !utf8.ValidString(c) {
+			return []byte(c), nil
+		}
+	case time.Duration:
+		// stored in the form every encoder writes it in
+		return c.String(), nil
+	}
+	return v, nil
+}
+
+// keyString renders a non-string map key as a string, in the form of the
+// value yaml.v2 resolved it to: both `y` and `yes` give "true", and `0x10`
+// gives "16"
+func keyString(k interface{}) string {
+	switch v := k.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return fmt.Sprint(k)
+}
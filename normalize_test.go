@@ -0,0 +1,76 @@
+package anyvalue
+
+import (
+	"testing"
+)
+
+func TestYamlMutable(t *testing.T) {
+	config, err := LoadConfigYaml("./config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config.Del("gin")
+	if config.Has("gin") {
+		t.Fatal("Del had no effect on a yaml document")
+	}
+
+	config.Set("redis.db", 1)
+	if config.Get("redis.addr").AsStr() != "127.0.0.1:6379" || config.Get("redis.db").AsInt() != 1 {
+		t.Fatal("Set lost yaml data")
+	}
+	if config.Get("listen").AsStr() != ":8081" {
+		t.Fatal("Set lost yaml root data")
+	}
+}
+
+func TestYamlKeys(t *testing.T) {
+	doc := []byte("ports:\n  80: http\n  443: https\nflags:\n  true: on\nlist:\n  - 1: a\n")
+
+	av, err := NewFromYaml(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if av.Get("ports.80").AsStr() != "http" || av.Get("ports.443").AsStr() != "https" {
+		t.Fatal("int keys not stringified")
+	}
+	if !av.Get("flags.true").AsBool() {
+		t.Fatal("bool key not stringified")
+	}
+	if av.Get("list[0].1").AsStr() != "a" {
+		t.Fatal("nested key not stringified")
+	}
+
+	if _, err := NewFromYaml(doc, StrictKeys()); err == nil {
+		t.Fatal("expected StrictKeys to reject int keys")
+	}
+}
+
+func TestNewFromInfNormalize(t *testing.T) {
+	av := NewFromInf(map[interface{}]interface{}{
+		"a": []interface{}{map[interface{}]interface{}{1: "x"}},
+	})
+	if !av.IsMap() || av.Get("a[0].1").AsStr() != "x" {
+		t.Fatal("NewFromInf not normalized")
+	}
+}
+
+func TestYamlBoolWordKeys(t *testing.T) {
+	doc := []byte("x: 1\ny: [1, 2]\n\"n\": quoted\n")
+
+	av, err := NewFromYaml(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// yaml.v2 resolves the YAML 1.1 word y to true
+	if av.Has("y") || len(av.Get("true").AsArray()) != 2 {
+		t.Fatalf("doc = %v", av.Interface())
+	}
+	if av.Get("n").AsStr() != "quoted" {
+		t.Fatal("quoted key not kept as written")
+	}
+
+	if _, err := NewFromYaml(doc, StrictKeys()); err == nil || err.Error() != "non-string key true (bool)" {
+		t.Fatalf("err = %v", err)
+	}
+}
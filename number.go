@@ -0,0 +1,317 @@
+package anyvalue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// Coercion selects how numeric accessors handle numbers that do not fit the
+// requested type
+type Coercion int
+
+const (
+	// Strict fails with a descriptive error on overflow, on loss of a
+	// fractional part, on sign change and on integers that a float cannot
+	// represent exactly
+	Strict Coercion = iota
+	// Lenient truncates fractions toward zero and wraps overflowing integers
+	// like a Go conversion does
+	Lenient
+)
+
+func (c Coercion) String() string {
+	switch c {
+	case Strict:
+		return "strict"
+	case Lenient:
+		return "lenient"
+	}
+	return "Coercion(" + strconv.Itoa(int(c)) + ")"
+}
+
+// DefaultCoercion is used by values that were not given a coercion with
+// WithCoercion. It should be set once during initialization.
+var DefaultCoercion = Strict
+
+// valueOptions holds the settings of a value view, they are inherited by
+// the values looked up from it
+type valueOptions struct {
+	coercion Coercion
+	// conversion is nil unless strings are converted, see WithConversion
+	conversion *Conversion
+}
+
+// options returns the settings of the value, or the package defaults
+func (j *AnyValue) options() valueOptions {
+	if j.opts != nil {
+		return *j.opts
+	}
+	return valueOptions{coercion: DefaultCoercion}
+}
+
+// view returns a value sharing the data of j with other settings
+func (j *AnyValue) view(o valueOptions) *AnyValue {
+	v := *j
+	v.opts = &o
+	return &v
+}
+
+// WithCoercion returns a view of the value, sharing its data, whose numeric
+// accessors and those of the values looked up from it use `c`:
+//
+//	cfg.WithCoercion(anyvalue.Lenient).Get("ratio").Int() // 1.9 -> 1
+func (j *AnyValue) WithCoercion(c Coercion) *AnyValue {
+	o := j.options()
+	o.coercion = c
+	return j.view(o)
+}
+
+// number returns the exact value of a number
+func (j *AnyValue) number() (*big.Rat, error) {
+	if r, ok := toRat(j.data); ok {
+		return r, nil
+	}
+	if s, ok := j.data.(string); ok && j.options().conversion != nil {
+		return j.parseNumber(s)
+	}
+	if j.Kind() != Number {
+		return nil, j.mismatch(Number)
+	}
+	if n, ok := j.data.(json.Number); ok {
+		if _, err := ParseDecimal(string(n)); !errors.Is(err, ErrOverflow) {
+			return nil, j.invalid(Number, "invalid number %q", string(n))
+		}
+	}
+	// a json.Number or a Decimal beyond maxDecimalScale, or a NaN or an
+	// infinity
+	return nil, j.outOfRange(fmt.Sprint(j.data))
+}
+
+// outOfRange reports a number too large or too precise to compute with
+func (j *AnyValue) outOfRange(text string) error {
+	return &PathError{
+		Path:     j.path.String(),
+		Expected: Number,
+		Actual:   j.Kind(),
+		Msg:      fmt.Sprintf("number %q out of range", text),
+		Err:      ErrOverflow,
+	}
+}
+
+// numberError describes a number that does not fit the requested type
+func (j *AnyValue) numberError(r *big.Rat, typ, problem string, err error) error {
+	return &PathError{
+		Path:     j.path.String(),
+		Expected: Number,
+		Actual:   Number,
+		Msg:      fmt.Sprintf("%s %s %s", formatRat(r), problem, typ),
+		Err:      err,
+	}
+}
+
+// signed coerces into a signed integer of `bits` bits
+func (j *AnyValue) signed(bits uint, typ string) (int64, error) {
+	r, err := j.number()
+	if err != nil {
+		return 0, err
+	}
+	n := new(big.Int).Quo(r.Num(), r.Denom())
+	min := new(big.Int).Lsh(big.NewInt(-1), bits-1)
+	max := new(big.Int).Sub(new(big.Int).Neg(min), big.NewInt(1))
+
+	if j.options().coercion == Strict {
+		if !r.IsInt() {
+			return 0, j.numberError(r, typ, "is not an integer, cannot convert to", ErrTypeMismatch)
+		}
+		if n.Cmp(min) < 0 || n.Cmp(max) > 0 {
+			return 0, j.numberError(r, typ, "overflows", ErrOverflow)
+		}
+		return n.Int64(), nil
+	}
+	return wrap(n, bits, true), nil
+}
+
+// unsigned coerces into an unsigned integer of `bits` bits
+func (j *AnyValue) unsigned(bits uint, typ string) (uint64, error) {
+	r, err := j.number()
+	if err != nil {
+		return 0, err
+	}
+	return j.unsignedRat(r, bits, typ)
+}
+
+func (j *AnyValue) unsignedRat(r *big.Rat, bits uint, typ string) (uint64, error) {
+	n := new(big.Int).Quo(r.Num(), r.Denom())
+	max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), bits), big.NewInt(1))
+
+	if j.options().coercion == Strict {
+		if !r.IsInt() {
+			return 0, j.numberError(r, typ, "is not an integer, cannot convert to", ErrTypeMismatch)
+		}
+		if n.Sign() < 0 {
+			return 0, j.numberError(r, typ, "is negative, cannot convert to", ErrOverflow)
+		}
+		if n.Cmp(max) > 0 {
+			return 0, j.numberError(r, typ, "overflows", ErrOverflow)
+		}
+		return n.Uint64(), nil
+	}
+	return uint64(wrap(n, bits, false)), nil
+}
+
+// wrap keeps the low `bits` bits of n in two's complement
+func wrap(n *big.Int, bits uint, signed bool) int64 {
+	mod := new(big.Int).Lsh(big.NewInt(1), bits)
+	m := new(big.Int).Mod(n, mod)
+	if signed && m.Cmp(new(big.Int).Rsh(mod, 1)) >= 0 {
+		m.Sub(m, mod)
+		return m.Int64()
+	}
+	return int64(m.Uint64())
+}
+
+// float coerces into a float of `bits` bits
+func (j *AnyValue) float(bits int, typ string) (float64, error) {
+	switch f := j.data.(type) {
+	case float64:
+		if bits == 64 || j.options().coercion == Lenient {
+			return f, nil
+		}
+	case float32:
+		return float64(f), nil
+	}
+	r, err := j.number()
+	if err != nil {
+		return 0, err
+	}
+
+	var f float64
+	var exact bool
+	if bits == 32 {
+		var f32 float32
+		f32, exact = r.Float32()
+		f = float64(f32)
+	} else {
+		f, exact = r.Float64()
+	}
+	if j.options().coercion == Strict {
+		if math.IsInf(f, 0) {
+			return 0, j.numberError(r, typ, "overflows", ErrOverflow)
+		}
+		if r.IsInt() && !exact {
+			return 0, j.numberError(r, typ, "cannot be represented exactly as", ErrOverflow)
+		}
+	}
+	return f, nil
+}
+
+// Int8 coerces into an int8
+func (j *AnyValue) Int8() (int8, error) {
+	i, err := j.signed(8, "int8")
+	return int8(i), err
+}
+
+// Int16 coerces into an int16
+func (j *AnyValue) Int16() (int16, error) {
+	i, err := j.signed(16, "int16")
+	return int16(i), err
+}
+
+// Int32 coerces into an int32
+func (j *AnyValue) Int32() (int32, error) {
+	i, err := j.signed(32, "int32")
+	return int32(i), err
+}
+
+// Uint coerces into an uint
+func (j *AnyValue) Uint() (uint, error) {
+	i, err := j.unsigned(strconv.IntSize, "uint")
+	return uint(i), err
+}
+
+// Uint8 coerces into an uint8
+func (j *AnyValue) Uint8() (uint8, error) {
+	i, err := j.unsigned(8, "uint8")
+	return uint8(i), err
+}
+
+// Uint16 coerces into an uint16
+func (j *AnyValue) Uint16() (uint16, error) {
+	i, err := j.unsigned(16, "uint16")
+	return uint16(i), err
+}
+
+// Uint32 coerces into an uint32
+func (j *AnyValue) Uint32() (uint32, error) {
+	i, err := j.unsigned(32, "uint32")
+	return uint32(i), err
+}
+
+// Float32 coerces into a float32
+func (j *AnyValue) Float32() (float32, error) {
+	f, err := j.float(32, "float32")
+	return float32(f), err
+}
+
+// bigFloatPrec is the precision BigFloat rounds fractions to
+const bigFloatPrec = 256
+
+// BigInt returns the value as an arbitrary-precision integer, such as an ID
+// above 2^53 decoded as a json.Number. Under Strict coercion a fractional
+// part is an error, under Lenient it is truncated toward zero.
+func (j *AnyValue) BigInt() (*big.Int, error) {
+	if n, ok := j.data.(*big.Int); ok {
+		return new(big.Int).Set(n), nil
+	}
+	r, err := j.number()
+	if err != nil {
+		return nil, err
+	}
+	if !r.IsInt() && j.options().coercion == Strict {
+		return nil, j.numberError(r, "big.Int", "is not an integer, cannot convert to", ErrTypeMismatch)
+	}
+	return new(big.Int).Quo(r.Num(), r.Denom()), nil
+}
+
+// BigFloat returns the value as an arbitrary-precision float. Integers are
+// exact, fractions are rounded to 256 bits unless the value already is a
+// *big.Float or a float64.
+func (j *AnyValue) BigFloat() (*big.Float, error) {
+	switch f := j.data.(type) {
+	case *big.Float:
+		return new(big.Float).Copy(f), nil
+	case float64:
+		return big.NewFloat(f), nil
+	case float32:
+		return big.NewFloat(float64(f)), nil
+	}
+	r, err := j.number()
+	if err != nil {
+		return nil, err
+	}
+	prec := uint(bigFloatPrec)
+	if n := uint(r.Num().BitLen()); r.IsInt() && n > prec {
+		prec = n
+	}
+	return new(big.Float).SetPrec(prec).SetRat(r), nil
+}
+
+// Decimal returns the value as an exact decimal, keeping the digits of a
+// json.Number as written. Floats give their shortest decimal
+// representation, 0.1 is 0.1 and not the binary approximation.
+func (j *AnyValue) Decimal() (Decimal, error) {
+	if d, ok := decimalOf(j.data); ok {
+		return d, nil
+	}
+	if s, ok := j.data.(string); ok && j.options().conversion != nil {
+		return j.parseDecimal(s)
+	}
+	if _, err := j.number(); err != nil {
+		return Decimal{}, err
+	}
+	return Decimal{}, j.mismatch(Number)
+}
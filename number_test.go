@@ -0,0 +1,110 @@
+package anyvalue
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestStrictCoercion(t *testing.T) {
+	doc, err := NewFromJson([]byte(`{"ratio":1.9,"neg":-1,"big":18446744073709551615,"byte":255,"id":9007199254740993,"port":70000}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := doc.Get("ratio").Int(); err == nil || !strings.Contains(err.Error(), "ratio: 1.9 is not an integer") {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := doc.Get("neg").Uint64(); err == nil || !strings.Contains(err.Error(), "negative") {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := doc.Get("big").Int64(); err == nil || !strings.Contains(err.Error(), "overflows int64") {
+		t.Fatalf("err = %v", err)
+	}
+	if u, err := doc.Get("big").Uint64(); err != nil || u != math.MaxUint64 {
+		t.Fatalf("Uint64() = %v, %v", u, err)
+	}
+	if _, err := doc.Get("byte").Int8(); err == nil {
+		t.Fatal("expected int8 overflow")
+	}
+	if b, err := doc.Get("byte").Uint8(); err != nil || b != 255 {
+		t.Fatalf("Uint8() = %v, %v", b, err)
+	}
+	if _, err := doc.Get("port").Uint16(); err == nil {
+		t.Fatal("expected uint16 overflow")
+	}
+	if p, err := doc.Get("port").Int32(); err != nil || p != 70000 {
+		t.Fatalf("Int32() = %v, %v", p, err)
+	}
+	if _, err := doc.Get("id").Float64(); err == nil {
+		t.Fatal("expected precision loss error")
+	}
+	if f, err := doc.Get("ratio").Float32(); err != nil || f != 1.9 {
+		t.Fatalf("Float32() = %v, %v", f, err)
+	}
+	if _, err := NewFromInf(1e300).Float32(); err == nil {
+		t.Fatal("expected float32 overflow")
+	}
+	if doc.Get("ratio").AsInt(7) != 7 {
+		t.Fatal("AsInt ignored the strict error")
+	}
+	if _, err := doc.Get("missing").Int(); err == nil {
+		t.Fatal("expected error on a missing value")
+	}
+}
+
+func TestLenientCoercion(t *testing.T) {
+	doc, err := NewFromYaml([]byte("ratio: -1.9\nneg: -1\nbyte: 300\nid: 9007199254740993\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lenient := doc.WithCoercion(Lenient)
+	if i, err := lenient.Get("ratio").Int(); err != nil || i != -1 {
+		t.Fatalf("Int() = %v, %v", i, err)
+	}
+	if u, err := lenient.Get("neg").Uint64(); err != nil || u != math.MaxUint64 {
+		t.Fatalf("Uint64() = %v, %v", u, err)
+	}
+	if b, err := lenient.Get("byte").Int8(); err != nil || b != 44 {
+		t.Fatalf("Int8() = %v, %v", b, err)
+	}
+	if _, err := lenient.Get("id").Float64(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := doc.Get("ratio").Int(); err == nil {
+		t.Fatal("view changed the coercion of the original value")
+	}
+
+	DefaultCoercion = Lenient
+	defer func() { DefaultCoercion = Strict }()
+	if i, err := doc.Get("ratio").Int(); err != nil || i != -1 {
+		t.Fatalf("Int() = %v, %v", i, err)
+	}
+	if _, err := doc.WithCoercion(Strict).Get("ratio").Int(); err == nil {
+		t.Fatal("per-value coercion ignored")
+	}
+}
+
+func TestNumberOutOfRange(t *testing.T) {
+	doc, err := NewFromJson([]byte(`{"a":1e9999999}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, get := range []func() error{
+		func() error { _, err := doc.Get("a").Int(); return err },
+		func() error { _, err := doc.Get("a").BigInt(); return err },
+		func() error { _, err := doc.Get("a").Decimal(); return err },
+	} {
+		if err := get(); err == nil || err.Error() != `a: number "1e9999999" out of range` || !errors.Is(err, ErrOverflow) {
+			t.Fatalf("err = %v", err)
+		}
+	}
+
+	bad := New().Set("n", json.Number("x1"))
+	if _, err := bad.Get("n").Int(); err == nil || err.Error() != `n: invalid number "x1"` {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,334 @@
+package anyvalue
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// ApplyPatch applies a RFC 6902 JSON Patch, an array of operations such as
+// {"op": "replace", "path": "/redis/max_conn", "value": 200}. The add,
+// remove, replace, move, copy and test operations are supported.
+//
+// The patch is atomic: when an operation fails, ApplyPatch returns its
+// error and the value is left untouched.
+func (j *AnyValue) ApplyPatch(patch *AnyValue) error {
+	ops, err := patch.Array()
+	if err != nil {
+		return patch.invalid(Array, "patch must be an array of operations")
+	}
+
+	doc := &AnyValue{data: deepCopy(j.data)}
+	for i, op := range ops {
+		if err := doc.applyOp(NewFromInf(op)); err != nil {
+			if le, ok := err.(*LookupError); ok {
+				err = &PathError{Path: le.Path, Expected: Unknown, Actual: Missing, Err: le}
+			}
+			return fmt.Errorf("patch operation %d: %w", i, err)
+		}
+	}
+	j.data = doc.data
+	return nil
+}
+
+func (j *AnyValue) applyOp(op *AnyValue) error {
+	path, err := op.pointerMember(nil, "path")
+	if err != nil {
+		return err
+	}
+	name, err := op.Get("op").Str()
+	if err != nil {
+		return patchError(path, ErrNotFound, `missing "op" member`)
+	}
+
+	switch name {
+	case "add", "replace", "test":
+		val, ok := op.Exist("value")
+		if !ok {
+			return patchError(path, ErrNotFound, `%s: missing "value" member`, name)
+		}
+		switch name {
+		case "add":
+			return j.patchAdd(path, deepCopy(val.data))
+		case "replace":
+			if err := j.patchExist(path); err != nil {
+				return err
+			}
+			return j.setPath(path.Path(), deepCopy(val.data), newSetOptions(nil))
+		}
+		cur, n, reason := path.Path().lookup(j.data)
+		if reason != MissNone {
+			return j.lookupError(path.Path(), n, reason)
+		}
+		if !deepEqual(cur, val.data) {
+			return patchError(path, ErrTypeMismatch, "test failed, value differs")
+		}
+		return nil
+	case "remove":
+		return j.delPath(path.Path())
+	case "move", "copy":
+		from, err := op.pointerMember(path, "from")
+		if err != nil {
+			return err
+		}
+		val, n, reason := from.Path().lookup(j.data)
+		if reason != MissNone {
+			return j.lookupError(from.Path(), n, reason)
+		}
+		if name == "copy" {
+			return j.patchAdd(path, deepCopy(val))
+		}
+		if isPointerPrefix(from, path) && len(from) < len(path) {
+			return patchError(path, ErrInvalidPath, "cannot move %q into itself", from.String())
+		}
+		if err := j.delPath(from.Path()); err != nil {
+			return err
+		}
+		return j.patchAdd(path, val)
+	}
+	return patchError(path, ErrTypeMismatch, "unknown operation %q", name)
+}
+
+// pointerMember parses the JSON Pointer in the `name` member of the
+// operation on `path`, which is nil while the path member is not parsed
+func (j *AnyValue) pointerMember(path Pointer, name string) (Pointer, error) {
+	s, err := j.Get(name).Str()
+	if err != nil {
+		return nil, patchError(path, ErrNotFound, "missing %q member", name)
+	}
+	return ParsePointer(s)
+}
+
+// patchError reports a malformed or failed operation on `ptr`
+func patchError(ptr Pointer, err error, format string, args ...interface{}) error {
+	return &PathError{
+		Path:     ptr.Path().String(),
+		Expected: Unknown,
+		Actual:   Unknown,
+		Msg:      fmt.Sprintf(format, args...),
+		Err:      err,
+	}
+}
+
+// patchExist checks that the target of an operation exists
+func (j *AnyValue) patchExist(ptr Pointer) error {
+	_, n, reason := ptr.Path().lookup(j.data)
+	if reason != MissNone {
+		return j.lookupError(ptr.Path(), n, reason)
+	}
+	return nil
+}
+
+// patchAdd implements the add operation: the parent of the target must
+// exist, array elements are inserted rather than replaced
+func (j *AnyValue) patchAdd(ptr Pointer, val interface{}) error {
+	if len(ptr) == 0 {
+		j.data = val
+		return nil
+	}
+	parent := ptr[:len(ptr)-1]
+	cur, n, reason := parent.Path().lookup(j.data)
+	if reason != MissNone {
+		return j.lookupError(parent.Path(), n, reason)
+	}
+
+	last := ptr[len(ptr)-1]
+	switch c := cur.(type) {
+	case map[string]interface{}:
+		c[last] = val
+		return nil
+	case []interface{}:
+		i := len(c)
+		if last != "-" {
+			var err error
+			if !isPointerIndex(last) {
+				return &PathError{
+					Path:     ptr.Path().String(),
+					Expected: Array,
+					Actual:   Array,
+					Msg:      fmt.Sprintf("invalid array index %q", last),
+					Err:      ErrInvalidPath,
+				}
+			}
+			if i, err = strconv.Atoi(last); err != nil || i > len(c) {
+				return &PathError{
+					Path:     ptr.Path().String(),
+					Expected: Array,
+					Actual:   Array,
+					Msg:      fmt.Sprintf("index %q out of range", last),
+					Err:      ErrNotFound,
+				}
+			}
+		}
+		a := make([]interface{}, 0, len(c)+1)
+		a = append(append(append(a, c[:i]...), val), c[i:]...)
+		return j.setPath(parent.Path(), a, newSetOptions(nil))
+	}
+	return &PathError{
+		Path:     parent.Path().String(),
+		Expected: Object,
+		Actual:   kindOf(cur),
+		Msg:      fmt.Sprintf("parent of %q is not a container", ptr.String()),
+		Err:      ErrTypeMismatch,
+	}
+}
+
+// isPointerPrefix reports whether p is a prefix of q
+func isPointerPrefix(p, q Pointer) bool {
+	if len(p) > len(q) {
+		return false
+	}
+	for i := range p {
+		if p[i] != q[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Diff returns the RFC 6902 JSON Patch that turns `a` into `b`. Maps are
+// compared key by key and arrays through their longest common subsequence,
+// so the patch only touches what changed.
+func Diff(a, b *AnyValue) *AnyValue {
+	ops := []interface{}{}
+	diffPatch(&ops, Pointer{}, a.data, b.data)
+	return &AnyValue{data: ops}
+}
+
+func patchOp(op string, ptr Pointer, val interface{}, withValue bool) map[string]interface{} {
+	m := map[string]interface{}{"op": op, "path": ptr.String()}
+	if withValue {
+		m["value"] = deepCopy(val)
+	}
+	return m
+}
+
+func diffPatch(ops *[]interface{}, ptr Pointer, a, b interface{}) {
+	if deepEqual(a, b) {
+		return
+	}
+	child := func(tok string) Pointer {
+		return append(append(Pointer{}, ptr...), tok)
+	}
+
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for _, k := range sortedKeys(x) {
+			if f, ok := y[k]; ok {
+				diffPatch(ops, child(k), x[k], f)
+			} else {
+				*ops = append(*ops, patchOp("remove", child(k), nil, false))
+			}
+		}
+		for _, k := range sortedKeys(y) {
+			if _, ok := x[k]; !ok {
+				*ops = append(*ops, patchOp("add", child(k), y[k], true))
+			}
+		}
+		return
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		diffArrays(ops, ptr, x, y)
+		return
+	}
+	*ops = append(*ops, patchOp("replace", ptr, b, true))
+}
+
+// maxLCSCells bounds the table used to align arrays, larger arrays are
+// compared element by element
+const maxLCSCells = 1 << 20
+
+// diffArrays aligns x and y on their longest common subsequence, elements
+// that are replaced in place are diffed recursively
+func diffArrays(ops *[]interface{}, ptr Pointer, x, y []interface{}) {
+	child := func(i int) Pointer {
+		return append(append(Pointer{}, ptr...), strconv.Itoa(i))
+	}
+
+	start := 0
+	for start < len(x) && start < len(y) && deepEqual(x[start], y[start]) {
+		start++
+	}
+	ex, ey := len(x), len(y)
+	for ex > start && ey > start && deepEqual(x[ex-1], y[ey-1]) {
+		ex--
+		ey--
+	}
+	mx, my := x[start:ex], y[start:ey]
+
+	// lcs[i][k] is the length of the common subsequence of mx[i:] and my[k:]
+	var lcs [][]int
+	if (len(mx)+1)*(len(my)+1) <= maxLCSCells {
+		lcs = make([][]int, len(mx)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(my)+1)
+		}
+		for i := len(mx) - 1; i >= 0; i-- {
+			for k := len(my) - 1; k >= 0; k-- {
+				switch {
+				case deepEqual(mx[i], my[k]):
+					lcs[i][k] = lcs[i+1][k+1] + 1
+				case lcs[i+1][k] >= lcs[i][k+1]:
+					lcs[i][k] = lcs[i+1][k]
+				default:
+					lcs[i][k] = lcs[i][k+1]
+				}
+			}
+		}
+	}
+
+	// pos is the index in the array as patched so far
+	pos := start
+	var dels, ins []interface{}
+	flush := func() {
+		k := 0
+		for ; k < len(dels) && k < len(ins); k++ {
+			diffPatch(ops, child(pos), dels[k], ins[k])
+			pos++
+		}
+		for r := k; r < len(dels); r++ {
+			*ops = append(*ops, patchOp("remove", child(pos), nil, false))
+		}
+		for ; k < len(ins); k++ {
+			*ops = append(*ops, patchOp("add", child(pos), ins[k], true))
+			pos++
+		}
+		dels, ins = dels[:0], ins[:0]
+	}
+
+	i, k := 0, 0
+	for lcs != nil && i < len(mx) && k < len(my) {
+		switch {
+		case deepEqual(mx[i], my[k]):
+			flush()
+			pos++
+			i++
+			k++
+		case lcs[i+1][k] >= lcs[i][k+1]:
+			dels = append(dels, mx[i])
+			i++
+		default:
+			ins = append(ins, my[k])
+			k++
+		}
+	}
+	dels = append(dels, mx[i:]...)
+	ins = append(ins, my[k:]...)
+	flush()
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
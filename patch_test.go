@@ -0,0 +1,93 @@
+package anyvalue
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestApplyPatch(t *testing.T) {
+	doc, err := NewFromJson([]byte(`{"redis":{"addr":"a","db":0},"servers":["x","z"],"old":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch, err := NewFromJson([]byte(`[
+		{"op":"test","path":"/redis/db","value":0},
+		{"op":"replace","path":"/redis/db","value":2},
+		{"op":"add","path":"/servers/1","value":"y"},
+		{"op":"add","path":"/servers/-","value":"w"},
+		{"op":"remove","path":"/servers/3"},
+		{"op":"move","from":"/old","path":"/new"},
+		{"op":"copy","from":"/redis","path":"/cache"},
+		{"op":"add","path":"/cache/addr","value":"b"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.ApplyPatch(patch); err != nil {
+		t.Fatal(err)
+	}
+
+	want, _ := NewFromJson([]byte(`{"redis":{"addr":"a","db":2},"servers":["x","y","z"],"new":1,"cache":{"addr":"b","db":2}}`))
+	if !deepEqual(doc.data, want.data) {
+		out, _ := doc.EncodeJson()
+		t.Fatalf("patched = %s", out)
+	}
+}
+
+func TestApplyPatchAtomic(t *testing.T) {
+	doc, err := NewFromYaml([]byte("a: 1\nb: [1, 2]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad := map[string]error{
+		`[{"op":"replace","path":"/a","value":2},{"op":"test","path":"/a","value":3}]`: ErrTypeMismatch,
+		`[{"op":"test","path":"/z","value":3}]`:                                        ErrNotFound,
+		`[{"op":"remove","path":"/a"},{"op":"remove","path":"/c"}]`:                    ErrNotFound,
+		`[{"op":"add","path":"/b/5","value":3}]`:                                       ErrNotFound,
+		`[{"op":"add","path":"/x/y","value":3}]`:                                       ErrNotFound,
+		`[{"op":"add","path":"/x"}]`:                                                   ErrNotFound,
+		`[{"path":"/a"}]`:                                                              ErrNotFound,
+		`[{"op":"remove"}]`:                                                            ErrNotFound,
+		`[{"op":"replace","path":"/c","value":3}]`:                                     ErrNotFound,
+		`[{"op":"move","from":"/b","path":"/b/0"}]`:                                    ErrInvalidPath,
+		`[{"op":"frobnicate","path":"/a"}]`:                                            ErrTypeMismatch,
+		`{"op":"remove","path":"/a"}`:                                                  ErrTypeMismatch,
+	}
+	for p, want := range bad {
+		patch, err := NewFromJson([]byte(p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = doc.ApplyPatch(patch)
+		var pe *PathError
+		if !errors.Is(err, want) || !errors.As(err, &pe) {
+			t.Fatalf("%s: err = %v, want %v", p, err, want)
+		}
+		if doc.Get("a").AsInt() != 1 || len(doc.Get("b").AsArray()) != 2 {
+			t.Fatalf("%s: document modified by a failed patch", p)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a, _ := NewFromYaml([]byte("a: 1\nb: [1, 2, 3, 4]\nc: {d: x, e: y}\nf: true\n"))
+	b, _ := NewFromJson([]byte(`{"a":1,"b":[1,3,4,5],"c":{"d":"z","g":1},"f":[1]}`))
+
+	patch := Diff(a, b)
+	ops := patch.AsArray()
+	if len(ops) != 6 {
+		out, _ := patch.EncodeJson()
+		t.Fatalf("patch = %s", out)
+	}
+
+	if err := a.ApplyPatch(patch); err != nil {
+		t.Fatal(err)
+	}
+	if !deepEqual(a.data, b.data) {
+		out, _ := a.EncodeJson()
+		t.Fatalf("patched = %s", out)
+	}
+	if len(Diff(a, b).AsArray()) != 0 {
+		t.Fatal("expected empty patch")
+	}
+}
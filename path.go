@@ -0,0 +1,572 @@
+package anyvalue
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SegmentKind tells how a PathSegment addresses its container
+type SegmentKind int
+
+const (
+	// SegmentKey addresses a map key. When the container is an array and the
+	// key is a decimal integer, it addresses the element at that index instead.
+	SegmentKey SegmentKind = iota
+	// SegmentQuoted addresses a map key that was quoted in the path, it never
+	// addresses an array element.
+	SegmentQuoted
+	// SegmentIndex addresses an array element, written as `[n]`.
+	SegmentIndex
+	// SegmentAppend addresses the slot past the end of an array, written as
+	// `[]`. A bare `-` key has the same meaning when the container is an array.
+	SegmentAppend
+)
+
+// PathSegment is a single step of a Path
+type PathSegment struct {
+	Kind  SegmentKind
+	Key   string
+	Index int
+}
+
+// Path is a parsed path expression as accepted by Get, Set, Has and Exist.
+//
+// The grammar is a dotted list of keys with optional array indexes:
+//
+//	servers.0.addr        bare keys, numeric keys also index arrays
+//	servers[0].addr       explicit index
+//	servers[-1]           negative indexes count from the end
+//	hosts."example.com"   quoted keys may contain any character
+//	hosts.example\.com    a backslash escapes the next character
+//	hosts["example.com"]  quoted keys may also be written in brackets
+//	servers[]             appends a new element when used with Set
+//	servers.-             same as servers[] when servers is an array
+//
+// The empty path addresses the value itself.
+type Path []PathSegment
+
+// PathSyntaxError is returned when a path expression cannot be parsed
+type PathSyntaxError struct {
+	Path   string
+	Offset int
+	Msg    string
+}
+
+func (e *PathSyntaxError) Error() string {
+	return fmt.Sprintf("invalid path %q: %s at offset %d", e.Path, e.Msg, e.Offset)
+}
+
+// Unwrap makes errors.Is match ErrInvalidPath
+func (e *PathSyntaxError) Unwrap() error {
+	return ErrInvalidPath
+}
+
+// ParsePath parses a path expression, see Path for the grammar
+func ParsePath(path string) (Path, error) {
+	p := &pathParser{src: path}
+	return p.parse()
+}
+
+// KeyPath returns a Path made of bare keys, without any parsing
+func KeyPath(keys ...string) Path {
+	p := make(Path, 0, len(keys))
+	for _, k := range keys {
+		p = append(p, PathSegment{Kind: SegmentKey, Key: k})
+	}
+	return p
+}
+
+type pathParser struct {
+	src string
+	pos int
+}
+
+func (p *pathParser) fail(msg string) error {
+	return &PathSyntaxError{Path: p.src, Offset: p.pos, Msg: msg}
+}
+
+func (p *pathParser) parse() (Path, error) {
+	var path Path
+	if p.src == "" {
+		return path, nil
+	}
+	// the first segment may be a bracket, every other one is introduced by
+	// a dot or a bracket
+	if p.src[0] != '[' {
+		seg, err := p.segment()
+		if err != nil {
+			return nil, err
+		}
+		path = append(path, seg)
+	}
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case '.':
+			p.pos++
+			seg, err := p.segment()
+			if err != nil {
+				return nil, err
+			}
+			path = append(path, seg)
+		case '[':
+			seg, err := p.bracket()
+			if err != nil {
+				return nil, err
+			}
+			path = append(path, seg)
+		default:
+			return nil, p.fail("expected '.' or '['")
+		}
+	}
+	return path, nil
+}
+
+// segment parses a bare or quoted key
+func (p *pathParser) segment() (PathSegment, error) {
+	if p.pos < len(p.src) && (p.src[p.pos] == '"' || p.src[p.pos] == '\'') {
+		key, err := p.quoted()
+		if err != nil {
+			return PathSegment{}, err
+		}
+		return PathSegment{Kind: SegmentQuoted, Key: key}, nil
+	}
+
+	var sb strings.Builder
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == '.' || c == '[' {
+			break
+		}
+		if c == ']' {
+			return PathSegment{}, p.fail("unexpected ']'")
+		}
+		if c == '\\' {
+			p.pos++
+			if p.pos == len(p.src) {
+				return PathSegment{}, p.fail("trailing backslash")
+			}
+			c = p.src[p.pos]
+		}
+		sb.WriteByte(c)
+		p.pos++
+	}
+	if p.pos == start {
+		return PathSegment{}, p.fail("empty key")
+	}
+	return PathSegment{Kind: SegmentKey, Key: sb.String()}, nil
+}
+
+// quoted parses a key enclosed in single or double quotes
+func (p *pathParser) quoted() (string, error) {
+	quote := p.src[p.pos]
+	p.pos++
+	var sb strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		p.pos++
+		if c == quote {
+			return sb.String(), nil
+		}
+		if c == '\\' {
+			if p.pos == len(p.src) {
+				break
+			}
+			c = p.src[p.pos]
+			p.pos++
+		}
+		sb.WriteByte(c)
+	}
+	return "", p.fail("unterminated quoted key")
+}
+
+// bracket parses `[n]`, `[]` or `["key"]`
+func (p *pathParser) bracket() (PathSegment, error) {
+	p.pos++
+	var seg PathSegment
+	if p.pos < len(p.src) && p.src[p.pos] == ']' {
+		p.pos++
+		return PathSegment{Kind: SegmentAppend}, nil
+	}
+	if p.pos < len(p.src) && (p.src[p.pos] == '"' || p.src[p.pos] == '\'') {
+		key, err := p.quoted()
+		if err != nil {
+			return seg, err
+		}
+		seg = PathSegment{Kind: SegmentQuoted, Key: key}
+	} else {
+		end := strings.IndexByte(p.src[p.pos:], ']')
+		if end < 0 {
+			return seg, p.fail("missing ']'")
+		}
+		i, ok := parseIndex(p.src[p.pos : p.pos+end])
+		if !ok {
+			return seg, p.fail("invalid array index")
+		}
+		seg = PathSegment{Kind: SegmentIndex, Index: i}
+		p.pos += end
+	}
+	if p.pos == len(p.src) || p.src[p.pos] != ']' {
+		return seg, p.fail("missing ']'")
+	}
+	p.pos++
+	return seg, nil
+}
+
+// parseIndex accepts an optionally negative decimal integer
+func parseIndex(s string) (int, bool) {
+	if s == "" || s[0] == '+' {
+		return 0, false
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, false
+	}
+	return i, true
+}
+
+// String renders the path back to its canonical textual form
+func (p Path) String() string {
+	var sb strings.Builder
+	for i, seg := range p {
+		if seg.Kind == SegmentIndex {
+			sb.WriteString("[" + strconv.Itoa(seg.Index) + "]")
+			continue
+		}
+		if seg.Kind == SegmentAppend {
+			sb.WriteString("[]")
+			continue
+		}
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		if seg.Kind == SegmentQuoted || !isBareKey(seg.Key) {
+			sb.WriteString(quoteKey(seg.Key))
+			continue
+		}
+		sb.WriteString(seg.Key)
+	}
+	return sb.String()
+}
+
+// quoteKey quotes a key so that the parser reads it back unchanged
+func quoteKey(key string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(key) + `"`
+}
+
+func isBareKey(key string) bool {
+	if key == "" || key[0] == '"' || key[0] == '\'' {
+		return false
+	}
+	return !strings.ContainsAny(key, ".[]\\")
+}
+
+// index returns the integer the segment stands for, if any
+func (s PathSegment) index() (int, bool) {
+	switch s.Kind {
+	case SegmentIndex:
+		return s.Index, true
+	case SegmentKey:
+		return parseIndex(s.Key)
+	}
+	return 0, false
+}
+
+// isAppend reports whether the segment addresses the slot past the end of
+// an array
+func (s PathSegment) isAppend() bool {
+	return s.Kind == SegmentAppend || s.Kind == SegmentKey && s.Key == "-"
+}
+
+// arrayIndex resolves the segment against an array of length n, negative
+// indexes count from the end
+func (s PathSegment) arrayIndex(n int) (int, bool) {
+	i, ok := s.index()
+	if !ok {
+		return 0, false
+	}
+	if i < 0 {
+		i += n
+	}
+	if i < 0 || i >= n {
+		return 0, false
+	}
+	return i, true
+}
+
+// MissReason tells why a lookup did not find a value
+type MissReason int
+
+const (
+	// MissNone means the value was found
+	MissNone MissReason = iota
+	// MissKeyAbsent means the parent map has no such key
+	MissKeyAbsent
+	// MissNotMap means a key was looked up in a value that is not a map
+	MissNotMap
+	// MissNotArray means an index was looked up in a value that is not an array
+	MissNotArray
+	// MissIndexOutOfRange means the index is past either end of the array
+	MissIndexOutOfRange
+	// MissInvalidPath means the path expression could not be parsed
+	MissInvalidPath
+)
+
+func (r MissReason) String() string {
+	switch r {
+	case MissNone:
+		return "found"
+	case MissKeyAbsent:
+		return "key absent"
+	case MissNotMap:
+		return "parent is not a map"
+	case MissNotArray:
+		return "parent is not an array"
+	case MissIndexOutOfRange:
+		return "index out of range"
+	case MissInvalidPath:
+		return "invalid path"
+	}
+	return "MissReason(" + strconv.Itoa(int(r)) + ")"
+}
+
+// LookupError describes why a lookup returned a missing value
+type LookupError struct {
+	// Path is the full path that was looked up
+	Path string
+	// At is the prefix of Path where the lookup stopped
+	At     string
+	Reason MissReason
+	// Err is the syntax error when Reason is MissInvalidPath
+	Err error
+}
+
+func (e *LookupError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("path %q: %s at %q", e.Path, e.Reason, e.At)
+}
+
+// Is makes errors.Is match ErrNotFound, or ErrInvalidPath when the path
+// could not be parsed
+func (e *LookupError) Is(target error) bool {
+	if e.Reason == MissInvalidPath {
+		return target == ErrInvalidPath
+	}
+	return target == ErrNotFound
+}
+
+// lookup walks the path from data and returns the addressed value, or the
+// number of segments that resolved and the reason the next one did not
+func (p Path) lookup(data interface{}) (interface{}, int, MissReason) {
+	cur := data
+	for i, seg := range p {
+		var reason MissReason
+		if cur, reason = seg.step(cur); reason != MissNone {
+			return nil, i, reason
+		}
+	}
+	return cur, len(p), MissNone
+}
+
+func (s PathSegment) step(cur interface{}) (interface{}, MissReason) {
+	switch c := cur.(type) {
+	case map[string]interface{}:
+		if s.Kind == SegmentIndex || s.Kind == SegmentAppend {
+			return nil, MissNotArray
+		}
+		v, ok := c[s.Key]
+		if !ok {
+			return nil, MissKeyAbsent
+		}
+		return v, MissNone
+	case []interface{}:
+		if _, ok := s.index(); !ok && !s.isAppend() {
+			return nil, MissNotMap
+		}
+		i, ok := s.arrayIndex(len(c))
+		if !ok {
+			return nil, MissIndexOutOfRange
+		}
+		return c[i], MissNone
+	}
+	if s.Kind == SegmentIndex || s.Kind == SegmentAppend {
+		return nil, MissNotArray
+	}
+	return nil, MissNotMap
+}
+
+type setOptions struct {
+	fill interface{}
+}
+
+// SetOption configures how Set and TrySet build missing containers
+type SetOption func(*setOptions)
+
+// WithFill sets the value stored in the slots that are skipped when an array
+// is extended past its end, the default is nil. Every skipped slot gets its
+// own copy of the maps and arrays in `fill`, so filling with an empty map
+// gives each slot a map of its own.
+func WithFill(fill interface{}) SetOption {
+	return func(o *setOptions) {
+		o.fill = fill
+	}
+}
+
+func newSetOptions(opts []SetOption) *setOptions {
+	o := &setOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+type delOptions struct {
+	prune bool
+}
+
+// DelOption configures how Del removes values
+type DelOption func(*delOptions)
+
+// WithPruneEmpty makes Del also remove the maps and arrays left empty by the
+// deletion, up to but not including the value Del is called on
+func WithPruneEmpty() DelOption {
+	return func(o *delOptions) {
+		o.prune = true
+	}
+}
+
+func newDelOptions(opts []DelOption) *delOptions {
+	o := &delOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// assign stores val at path p below cur and returns the new value for cur,
+// existing containers are only modified once the whole path is resolved
+func (p Path) assign(cur interface{}, val interface{}, o *setOptions) (interface{}, error) {
+	if len(p) == 0 {
+		return val, nil
+	}
+	switch c := cur.(type) {
+	case []interface{}:
+		return p.assignIndex(c, val, o)
+	case map[string]interface{}:
+		return p.assignKey(c, val, o)
+	}
+	// have to replace with something suitable
+	if p[0].Kind == SegmentIndex || p[0].Kind == SegmentAppend {
+		return p.assignIndex(nil, val, o)
+	}
+	return p.assignKey(make(map[string]interface{}), val, o)
+}
+
+// maxArrayGrowth is the number of slots Set fills when it extends an array
+// past its end, so that a stray index cannot allocate gigabytes
+const maxArrayGrowth = 1 << 16
+
+func (p Path) assignIndex(a []interface{}, val interface{}, o *setOptions) (interface{}, error) {
+	seg := p[0]
+	i := len(a)
+	if !seg.isAppend() {
+		var ok bool
+		if i, ok = seg.index(); !ok {
+			return nil, &PathError{
+				Expected: Object,
+				Actual:   Array,
+				Msg:      fmt.Sprintf("key %q on an array value", seg.Key),
+				Err:      ErrTypeMismatch,
+			}
+		}
+		k := i
+		if k < 0 {
+			k += len(a)
+		}
+		if k < 0 || k-len(a) > maxArrayGrowth {
+			return nil, indexError(i, len(a))
+		}
+		i = k
+	}
+
+	var child interface{}
+	if i < len(a) {
+		child = a[i]
+	}
+	v, err := p[1:].assign(child, val, o)
+	if err != nil {
+		return nil, err
+	}
+	if i < len(a) {
+		a[i] = v
+		return a, nil
+	}
+	for len(a) < i {
+		a = append(a, deepCopy(o.fill))
+	}
+	return append(a, v), nil
+}
+
+func (p Path) assignKey(m map[string]interface{}, val interface{}, o *setOptions) (interface{}, error) {
+	seg := p[0]
+	if seg.Kind == SegmentIndex || seg.Kind == SegmentAppend {
+		return nil, &PathError{
+			Expected: Array,
+			Actual:   Object,
+			Msg:      fmt.Sprintf("index %s on a map value", Path{seg}),
+			Err:      ErrTypeMismatch,
+		}
+	}
+	v, err := p[1:].assign(m[seg.Key], val, o)
+	if err != nil {
+		return nil, err
+	}
+	m[seg.Key] = v
+	return m, nil
+}
+
+// remove deletes the value addressed by p below cur and returns the new
+// value for cur, or the number of segments that resolved and the reason the
+// next one did not. Array elements are removed by shifting the following
+// ones down.
+func (p Path) remove(cur interface{}) (interface{}, int, MissReason) {
+	if len(p) == 0 {
+		return nil, 0, MissNone
+	}
+	seg := p[0]
+	child, reason := seg.step(cur)
+	if reason != MissNone {
+		return nil, 0, reason
+	}
+
+	switch c := cur.(type) {
+	case map[string]interface{}:
+		if len(p) == 1 {
+			delete(c, seg.Key)
+			return c, 1, MissNone
+		}
+		v, n, reason := p[1:].remove(child)
+		if reason != MissNone {
+			return nil, n + 1, reason
+		}
+		c[seg.Key] = v
+		return c, len(p), MissNone
+	case []interface{}:
+		i, _ := seg.arrayIndex(len(c))
+		if len(p) == 1 {
+			a := make([]interface{}, 0, len(c)-1)
+			return append(append(a, c[:i]...), c[i+1:]...), 1, MissNone
+		}
+		v, n, reason := p[1:].remove(child)
+		if reason != MissNone {
+			return nil, n + 1, reason
+		}
+		c[i] = v
+		return c, len(p), MissNone
+	}
+	return nil, 0, MissNotMap
+}
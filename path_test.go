@@ -0,0 +1,191 @@
+package anyvalue
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	cases := map[string]string{
+		"":                    "",
+		"a.b.c":               "a.b.c",
+		"servers.0.addr":      "servers.0.addr",
+		"servers[0].addr":     "servers[0].addr",
+		"servers[-1]":         "servers[-1]",
+		`hosts."example.com"`: `hosts."example.com"`,
+		`hosts.example\.com`:  `hosts."example.com"`,
+		`hosts['a"b']`:        `hosts."a\"b"`,
+		"[1][2]":              "[1][2]",
+	}
+	for in, want := range cases {
+		p, err := ParsePath(in)
+		if err != nil {
+			t.Fatalf("ParsePath(%q): %v", in, err)
+		}
+		if p.String() != want {
+			t.Fatalf("ParsePath(%q) = %q, want %q", in, p.String(), want)
+		}
+	}
+
+	for _, in := range []string{"a..b", ".a", "a.", "a[", "a[x]", "a[0]b", `a."b`, `a\`, "a]"} {
+		_, err := ParsePath(in)
+		if _, ok := err.(*PathSyntaxError); !ok {
+			t.Fatalf("ParsePath(%q) error = %v, want *PathSyntaxError", in, err)
+		}
+	}
+}
+
+func TestGetArrayPath(t *testing.T) {
+	av, err := NewFromJson([]byte(`{"servers":[{"addr":"a"},{"addr":"b"}],"hosts":{"example.com":1}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := av.Get("servers.0.addr").AsStr(); s != "a" {
+		t.Fatalf("servers.0.addr = %q", s)
+	}
+	if s := av.Get("servers[1].addr").AsStr(); s != "b" {
+		t.Fatalf("servers[1].addr = %q", s)
+	}
+	if s := av.Get("servers[-1].addr").AsStr(); s != "b" {
+		t.Fatalf("servers[-1].addr = %q", s)
+	}
+	if !av.Has(`hosts."example.com"`) || !av.Has(`hosts.example\.com`) {
+		t.Fatal("quoted key not found")
+	}
+	if av.Has("servers[2]") || av.Has(`servers."0"`) || av.Has("hosts[0]") || av.Has("servers[") {
+		t.Fatal("unexpected match")
+	}
+	if _, ok := av.Exist("servers.1"); !ok {
+		t.Fatal("servers.1 not found")
+	}
+}
+
+func TestSetPathSyntax(t *testing.T) {
+	av, err := NewFromJson([]byte(`{"servers":[{"addr":"a"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	av.Set("servers[0].port", 80).Set(`hosts."example.com"`, true)
+
+	if av.Get("servers.0.port").AsInt() != 80 {
+		t.Fatal("servers.0.port not set")
+	}
+	if !av.Get(`hosts."example.com"`).AsBool() {
+		t.Fatal("quoted key not set")
+	}
+	if err := av.TrySet("servers[", 1); err == nil {
+		t.Fatal("expected syntax error")
+	}
+	if err := av.TrySet("servers[-3].port", 1); err == nil || err.Error() != "servers[-3].port: index -3 out of range for length 1" || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestSetGrowArray(t *testing.T) {
+	av, err := NewFromJson([]byte(`{"servers":[{"addr":"a"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	av.Set("servers.2.port", 80, WithFill(false))
+	servers := av.Get("servers").AsArray()
+	if len(servers) != 3 || servers[1] != false {
+		t.Fatalf("servers = %v", servers)
+	}
+	if av.Get("servers[0].addr").AsStr() != "a" || av.Get("servers[2].port").AsInt() != 80 {
+		t.Fatal("servers not updated in place")
+	}
+
+	filled := New()
+	filled.Set("servers[2].port", 80, WithFill(map[string]interface{}{}))
+	filled.Set("servers[0].addr", "a")
+	if filled.Has("servers[1].addr") {
+		t.Fatal("skipped slots share the fill value")
+	}
+
+	av.Set("servers[].addr", "d").Set("servers.-", "e")
+	if n := len(av.Get("servers").AsArray()); n != 5 {
+		t.Fatalf("len(servers) = %d, want 5", n)
+	}
+	if av.Get("servers[3].addr").AsStr() != "d" || av.Get("servers[-1]").AsStr() != "e" {
+		t.Fatal("append failed")
+	}
+
+	if err := av.TrySet("servers.name", "x"); err == nil {
+		t.Fatal("expected error setting a key on an array")
+	}
+	if !av.Get("servers").IsArray() {
+		t.Fatal("servers clobbered")
+	}
+
+	av = New().Set("list[1][]", "x")
+	out, err := av.EncodeJson()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"list":[null,["x"]]}` {
+		t.Fatalf("out = %s", out)
+	}
+
+	big := New().Set("a", []interface{}{})
+	if err := big.TrySet("a[1000000000]", 1); !errors.Is(err, ErrNotFound) || big.Get("a").Len() != 0 {
+		t.Fatalf("err = %v, doc = %v", err, big.Interface())
+	}
+	if err := big.SetPointer("/a/1000000000", 1); !errors.Is(err, ErrNotFound) || big.Get("a").Len() != 0 {
+		t.Fatalf("err = %v, doc = %v", err, big.Interface())
+	}
+	if err := big.TrySet("a[65536]", 1); err != nil || big.Get("a").Len() != 65537 {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestMissingValue(t *testing.T) {
+	av, err := NewFromJson([]byte(`{"redis":{"addr":"a"},"servers":[1],"n":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]MissReason{
+		"redis.port":  MissKeyAbsent,
+		"n.x":         MissNotMap,
+		"servers.x":   MissNotMap,
+		"redis[0]":    MissNotArray,
+		"servers[3]":  MissIndexOutOfRange,
+		"servers.-1x": MissNotMap,
+		"redis[":      MissInvalidPath,
+	}
+	for path, reason := range cases {
+		v, ok := av.Exist(path)
+		if ok {
+			t.Fatalf("%s: unexpected match", path)
+		}
+		lerr, isLookup := v.Err().(*LookupError)
+		if !isLookup || lerr.Reason != reason {
+			t.Fatalf("%s: Err() = %v, want reason %v", path, v.Err(), reason)
+		}
+	}
+
+	miss := av.Get("redis").Get("missing").Get("deeper")
+	if lerr := miss.Err().(*LookupError); lerr.Path != "redis.missing.deeper" || lerr.At != "redis.missing" {
+		t.Fatalf("Err() = %+v", lerr)
+	}
+
+	if miss.Has("") || miss.Get("").Err() == nil {
+		t.Fatal("empty path on a missing value was found")
+	}
+
+	miss.Set("x", 1)
+	if miss.Err() != nil || miss.Get("x").AsInt() != 1 {
+		t.Fatal("Set on a missing value failed")
+	}
+	if !AVNil.IsMissing() || AVNil.Kind() != Missing {
+		t.Fatal("AVNil is not missing")
+	}
+	if av.Get("other").Has("x") || AVNil.Interface() != nil {
+		t.Fatal("missing values are shared")
+	}
+	if av.GetIndex(0).Err() == nil || av.Get("servers").GetIndex(-1).AsInt() != 1 {
+		t.Fatal("GetIndex failed")
+	}
+}
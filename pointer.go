@@ -0,0 +1,105 @@
+package anyvalue
+
+import (
+	"strings"
+)
+
+// Pointer is a parsed RFC 6901 JSON Pointer, it holds the unescaped
+// reference tokens. The empty Pointer addresses the whole document.
+type Pointer []string
+
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// ParsePointer parses a JSON Pointer such as "/servers/0/addr"
+func ParsePointer(ptr string) (Pointer, error) {
+	if ptr == "" {
+		return Pointer{}, nil
+	}
+	if ptr[0] != '/' {
+		return nil, &PathSyntaxError{Path: ptr, Msg: "pointer must start with '/'"}
+	}
+	tokens := strings.Split(ptr[1:], "/")
+	offset := 1
+	for i, tok := range tokens {
+		for k := 0; k < len(tok); k++ {
+			if tok[k] == '~' && (k+1 == len(tok) || tok[k+1] != '0' && tok[k+1] != '1') {
+				return nil, &PathSyntaxError{Path: ptr, Offset: offset + k, Msg: "invalid '~' escape"}
+			}
+		}
+		offset += len(tok) + 1
+		tokens[i] = pointerUnescaper.Replace(tok)
+	}
+	return Pointer(tokens), nil
+}
+
+// String renders the pointer with `~` and `/` escaped as `~0` and `~1`
+func (p Pointer) String() string {
+	var sb strings.Builder
+	for _, tok := range p {
+		sb.WriteByte('/')
+		sb.WriteString(pointerEscaper.Replace(tok))
+	}
+	return sb.String()
+}
+
+// Path converts the pointer to a Path. Tokens address array elements only
+// when they are RFC 6901 array indexes, or `-` for the end of the array.
+func (p Pointer) Path() Path {
+	path := make(Path, 0, len(p))
+	for _, tok := range p {
+		kind := SegmentQuoted
+		if tok == "-" || isPointerIndex(tok) {
+			kind = SegmentKey
+		}
+		path = append(path, PathSegment{Kind: kind, Key: tok})
+	}
+	return path
+}
+
+// isPointerIndex reports whether tok is "0" or a decimal without leading zero
+func isPointerIndex(tok string) bool {
+	if tok == "" || len(tok) > 1 && tok[0] == '0' {
+		return false
+	}
+	for i := 0; i < len(tok); i++ {
+		if tok[i] < '0' || tok[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// GetPointer returns the value addressed by the JSON Pointer `ptr`
+//
+//	js.GetPointer("/servers/0/addr").AsStr()
+func (j *AnyValue) GetPointer(ptr string) *AnyValue {
+	p, err := ParsePointer(ptr)
+	if err != nil {
+		return j.missingPath(ptr, err)
+	}
+	return j.getPath(p.Path())
+}
+
+// SetPointer writes `val` at the JSON Pointer `ptr` with the same rules as
+// Set, a final `-` token appends to an array
+func (j *AnyValue) SetPointer(ptr string, val interface{}, opts ...SetOption) error {
+	p, err := ParsePointer(ptr)
+	if err != nil {
+		return err
+	}
+	return j.setPath(p.Path(), val, newSetOptions(opts))
+}
+
+// DelPointer deletes the value addressed by the JSON Pointer `ptr`, array
+// elements after a deleted one are shifted down. It fails with a
+// *LookupError when nothing is found at `ptr`.
+func (j *AnyValue) DelPointer(ptr string) error {
+	p, err := ParsePointer(ptr)
+	if err != nil {
+		return err
+	}
+	return j.delPath(p.Path())
+}
@@ -0,0 +1,70 @@
+package anyvalue
+
+import (
+	"testing"
+)
+
+func TestParsePointer(t *testing.T) {
+	p, err := ParsePointer("/a~1b/m~0n/0/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p) != 4 || p[0] != "a/b" || p[1] != "m~n" || p[2] != "0" || p[3] != "" {
+		t.Fatalf("ParsePointer = %q", []string(p))
+	}
+	if p.String() != "/a~1b/m~0n/0/" {
+		t.Fatalf("String() = %q", p.String())
+	}
+
+	for _, ptr := range []string{"a/b", "/a~", "/a~2"} {
+		if _, err := ParsePointer(ptr); err == nil {
+			t.Fatalf("ParsePointer(%q): expected error", ptr)
+		}
+	}
+}
+
+func TestPointer(t *testing.T) {
+	av, err := NewFromJson([]byte(`{"servers":[{"addr":"a"},{"addr":"b"}],"hosts":{"example.com":1,"a/b":2,"01":3}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if av.GetPointer("/servers/1/addr").AsStr() != "b" {
+		t.Fatal("array pointer failed")
+	}
+	if av.GetPointer("/hosts/example.com").AsInt() != 1 || av.GetPointer("/hosts/a~1b").AsInt() != 2 {
+		t.Fatal("escaped pointer failed")
+	}
+	if av.GetPointer("/hosts/01").AsInt() != 3 || av.GetPointer("/servers/01").Err() == nil {
+		t.Fatal("leading zero handled as an index")
+	}
+	if av.GetPointer("/servers/-1").Err() == nil {
+		t.Fatal("negative index accepted")
+	}
+	if !av.GetPointer("").IsMap() {
+		t.Fatal("empty pointer is not the document")
+	}
+
+	if err := av.SetPointer("/servers/-", map[string]interface{}{"addr": "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := av.SetPointer("/hosts/x.y~1z", 4); err != nil {
+		t.Fatal(err)
+	}
+	if av.Get("servers[2].addr").AsStr() != "c" || av.GetPointer("/hosts/x.y~1z").AsInt() != 4 {
+		t.Fatal("SetPointer failed")
+	}
+
+	if err := av.DelPointer("/servers/0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := av.DelPointer("/hosts/a~1b"); err != nil {
+		t.Fatal(err)
+	}
+	if av.Get("servers[0].addr").AsStr() != "b" || len(av.Get("servers").AsArray()) != 2 || av.Has(`hosts."a/b"`) {
+		t.Fatal("DelPointer failed")
+	}
+	if err := av.DelPointer("/servers/5"); err == nil {
+		t.Fatal("expected error deleting a missing element")
+	}
+}
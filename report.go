@@ -0,0 +1,193 @@
+package anyvalue
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ChangeType classifies an entry of a Report
+type ChangeType int
+
+const (
+	// Added means the path only exists in the new value
+	Added ChangeType = iota
+	// Removed means the path only exists in the old value
+	Removed
+	// Changed means the value at the path changed but kept its type
+	Changed
+	// TypeChanged means the value at the path changed type, such as a
+	// string that became a number or a map that became an array
+	TypeChanged
+)
+
+func (t ChangeType) String() string {
+	switch t {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Changed:
+		return "changed"
+	case TypeChanged:
+		return "type-changed"
+	}
+	return fmt.Sprintf("ChangeType(%d)", int(t))
+}
+
+// Change is a single difference between two values
+type Change struct {
+	Type ChangeType
+	// Path is the dotted path of the change, empty for the root
+	Path string
+	// Old is nil for Added changes
+	Old interface{}
+	// New is nil for Removed changes
+	New interface{}
+}
+
+// Report lists the differences between two values, ordered by path
+type Report []Change
+
+// DiffReport compares `a` and `b` and lists what changed from `a` to `b`.
+// Maps are compared key by key and arrays index by index, so that every
+// change is reported at the deepest path where it happened.
+//
+// useful to review what changes between two versions of a config:
+//
+//	fmt.Print(anyvalue.DiffReport(current, next).Text())
+func DiffReport(a, b *AnyValue) Report {
+	r := Report{}
+	r.diff(nil, a.data, b.data)
+	return r
+}
+
+func (r *Report) add(t ChangeType, p Path, old, new interface{}) {
+	*r = append(*r, Change{Type: t, Path: p.String(), Old: deepCopy(old), New: deepCopy(new)})
+}
+
+func (r *Report) diff(p Path, a, b interface{}) {
+	if deepEqual(a, b) {
+		return
+	}
+	if kindOf(a) != kindOf(b) {
+		r.add(TypeChanged, p, a, b)
+		return
+	}
+	child := func(seg PathSegment) Path {
+		return append(append(Path{}, p...), seg)
+	}
+
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y := b.(map[string]interface{})
+		keys := sortedKeys(x)
+		for _, k := range sortedKeys(y) {
+			if _, ok := x[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			cp := child(PathSegment{Kind: SegmentKey, Key: k})
+			e, inA := x[k]
+			f, inB := y[k]
+			switch {
+			case !inB:
+				r.add(Removed, cp, e, nil)
+			case !inA:
+				r.add(Added, cp, nil, f)
+			default:
+				r.diff(cp, e, f)
+			}
+		}
+	case []interface{}:
+		y := b.([]interface{})
+		for i := 0; i < len(x) || i < len(y); i++ {
+			cp := child(PathSegment{Kind: SegmentIndex, Index: i})
+			switch {
+			case i >= len(y):
+				r.add(Removed, cp, x[i], nil)
+			case i >= len(x):
+				r.add(Added, cp, nil, y[i])
+			default:
+				r.diff(cp, x[i], y[i])
+			}
+		}
+	default:
+		r.add(Changed, p, a, b)
+	}
+}
+
+const (
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorReset  = "\x1b[0m"
+)
+
+// Text renders the report as unified diff style lines, `-` for the old
+// value and `+` for the new one:
+//
+//	- redis.addr: "127.0.0.1:6379"
+//	+ redis.addr: "10.0.0.1:6379"
+//	+ redis.pool: {"size":8}
+func (r Report) Text() string {
+	return r.render(false)
+}
+
+// ColorText is like Text with ANSI colors for terminals: removed lines are
+// red, added lines green and type changes yellow
+func (r Report) ColorText() string {
+	return r.render(true)
+}
+
+func (r Report) render(color bool) string {
+	var sb strings.Builder
+	line := func(c, sign, path string, v interface{}, typed bool) {
+		if color {
+			sb.WriteString(c)
+		}
+		if path == "" {
+			path = "(root)"
+		}
+		sb.WriteString(sign + " " + path + ": " + renderValue(v))
+		if typed {
+			sb.WriteString(" (" + kindOf(v).String() + ")")
+		}
+		if color {
+			sb.WriteString(colorReset)
+		}
+		sb.WriteByte('\n')
+	}
+
+	for _, c := range r {
+		switch c.Type {
+		case Added:
+			line(colorGreen, "+", c.Path, c.New, false)
+		case Removed:
+			line(colorRed, "-", c.Path, c.Old, false)
+		case Changed:
+			line(colorRed, "-", c.Path, c.Old, false)
+			line(colorGreen, "+", c.Path, c.New, false)
+		case TypeChanged:
+			line(colorYellow, "-", c.Path, c.Old, true)
+			line(colorYellow, "+", c.Path, c.New, true)
+		}
+	}
+	return sb.String()
+}
+
+// renderValue renders a value as compact JSON
+func renderValue(v interface{}) string {
+	data, err := newEncoder(formatJSON).encodable(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,67 @@
+package anyvalue
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffReport(t *testing.T) {
+	current, err := LoadConfigYaml("./config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := current.Clone()
+	next.Set("redis.addr", "10.0.0.1:6379").Set("redis.pool.size", 8).Set("listen", 8081).
+		Set("routes", []interface{}{"a"})
+	next.Del("gin")
+
+	r := DiffReport(current, next)
+	want := []Change{
+		{Type: Removed, Path: "gin"},
+		{Type: TypeChanged, Path: "listen"},
+		{Type: Changed, Path: "redis.addr"},
+		{Type: Added, Path: "redis.pool"},
+		{Type: Added, Path: "routes"},
+	}
+	if len(r) != len(want) {
+		t.Fatalf("report = %+v", r)
+	}
+	for i, c := range want {
+		if r[i].Type != c.Type || r[i].Path != c.Path {
+			t.Fatalf("change %d = %v %s, want %v %s", i, r[i].Type, r[i].Path, c.Type, c.Path)
+		}
+	}
+	if r[2].Old != "127.0.0.1:6379" || r[2].New != "10.0.0.1:6379" {
+		t.Fatalf("change = %+v", r[2])
+	}
+
+	text := r.Text()
+	for _, line := range []string{
+		`- redis.addr: "127.0.0.1:6379"`,
+		`+ redis.addr: "10.0.0.1:6379"`,
+		`+ redis.pool: {"size":8}`,
+		`- listen: ":8081" (string)`,
+		`+ listen: 8081 (number)`,
+	} {
+		if !strings.Contains(text, line+"\n") {
+			t.Fatalf("text report misses %q:\n%s", line, text)
+		}
+	}
+	if !strings.Contains(r.ColorText(), colorGreen+`+ redis.pool: {"size":8}`+colorReset) {
+		t.Fatal("color report not colored")
+	}
+
+	if len(DiffReport(current, current.Clone())) != 0 {
+		t.Fatal("expected empty report")
+	}
+}
+
+func TestDiffReportArrays(t *testing.T) {
+	a, _ := NewFromJson([]byte(`{"routes":["10.0.0.0/8","172.16.0.0/12"],"hosts":{"a.b":1}}`))
+	b, _ := NewFromYaml([]byte("routes: [10.0.0.0/8, 192.168.0.0/16, 1.1.1.1/32]\nhosts: {a.b: 2}\n"))
+
+	r := DiffReport(a, b)
+	if len(r) != 3 || r[0].Path != `hosts."a.b"` || r[1].Path != "routes[1]" || r[2].Type != Added || r[2].Path != "routes[2]" {
+		t.Fatalf("report = %+v", r)
+	}
+}
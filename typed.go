@@ -0,0 +1,145 @@
+package anyvalue
+
+import (
+	"math/big"
+	"net"
+	"net/url"
+	"time"
+)
+
+// Get reads the value at `path` as a T with the accessor of that type, so
+// the coercion and conversion of `j` apply:
+//
+//	port, err := anyvalue.Get[uint16](cfg, "redis.port")
+//	timeout, err := anyvalue.Get[time.Duration](cfg, "http.timeout")
+//
+// Scalars, the types of the typed accessors (time.Duration, net.IP,
+// *big.Int, []string, ...), map[string]interface{}, []interface{},
+// interface{} and *AnyValue are supported, any other type such as a struct
+// is filled by Decode.
+func Get[T any](j *AnyValue, path string) (T, error) {
+	return As[T](j.Get(path))
+}
+
+// GetOr is like Get but returns `def` when the value is missing or cannot
+// be read as a T
+func GetOr[T any](j *AnyValue, path string, def T) T {
+	v, err := Get[T](j, path)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// MustGet is like Get but panics with a *MustError, for values a program
+// cannot run without
+func MustGet[T any](j *AnyValue, path string) T {
+	return must[T](j, path)
+}
+
+// As reads `j` itself as a T, see Get
+func As[T any](j *AnyValue) (T, error) {
+	var out T
+	var err error
+	switch p := any(&out).(type) {
+	case *string:
+		*p, err = j.Str()
+	case *bool:
+		*p, err = j.Bool()
+	case *int:
+		*p, err = j.Int()
+	case *int8:
+		*p, err = j.Int8()
+	case *int16:
+		*p, err = j.Int16()
+	case *int32:
+		*p, err = j.Int32()
+	case *int64:
+		*p, err = j.Int64()
+	case *uint:
+		*p, err = j.Uint()
+	case *uint8:
+		*p, err = j.Uint8()
+	case *uint16:
+		*p, err = j.Uint16()
+	case *uint32:
+		*p, err = j.Uint32()
+	case *uint64:
+		*p, err = j.Uint64()
+	case *float32:
+		*p, err = j.Float32()
+	case *float64:
+		*p, err = j.Float64()
+	case **big.Int:
+		*p, err = j.BigInt()
+	case **big.Float:
+		*p, err = j.BigFloat()
+	case *Decimal:
+		*p, err = j.Decimal()
+	case *time.Duration:
+		*p, err = j.Duration()
+	case *time.Time:
+		*p, err = j.Time()
+	case *[]byte:
+		*p, err = j.Bytes()
+	case *net.IP:
+		*p, err = j.IP()
+	case **net.IPNet:
+		*p, err = j.IPNet()
+	case **url.URL:
+		*p, err = j.URL()
+	case *map[string]interface{}:
+		*p, err = j.Map()
+	case *[]interface{}:
+		*p, err = j.Array()
+	case *[]string:
+		*p, err = j.StrArr()
+	case *[]bool:
+		*p, err = j.BoolArr()
+	case *[]int64:
+		*p, err = j.Int64Arr()
+	case *[]uint64:
+		*p, err = j.UInt64Arr()
+	case *[]float64:
+		*p, err = j.Float64Arr()
+	case *[]time.Duration:
+		*p, err = j.DurationArr()
+	case *[]map[string]interface{}:
+		*p, err = j.MapArr()
+	case *[]net.IP:
+		*p, err = j.IPArr()
+	case *[]*net.IPNet:
+		*p, err = j.IPNetArr()
+	case *[]*AnyValue:
+		*p, err = j.Elems()
+	case **AnyValue:
+		if j.miss != nil {
+			err = j.mismatch(Unknown)
+		} else {
+			*p = j
+		}
+	case *interface{}:
+		if j.miss != nil {
+			err = j.mismatch(Unknown)
+		} else {
+			*p = j.Interface()
+		}
+	default:
+		err = j.Decode(&out)
+	}
+	return out, err
+}
+
+// orDefault returns what `get` reads, or else the first of `defs`, or else
+// the zero value. Defaults past the first are ignored.
+func orDefault[T any](get func() (T, error), defs []T) T {
+	v, err := get()
+	if err == nil {
+		return v
+	}
+	if len(defs) > 0 {
+		return defs[0]
+	}
+	var zero T
+	return zero
+}
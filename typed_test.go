@@ -0,0 +1,59 @@
+package anyvalue
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestTypedGet(t *testing.T) {
+	config, err := LoadConfigYaml("./config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Set("gin.timeout", "30s").Set("routes", []interface{}{"10.0.0.0/8"})
+
+	if n, err := Get[uint16](config, "redis.max_conn"); err != nil || n != 100 {
+		t.Fatalf("Get[uint16] = %v, %v", n, err)
+	}
+	if _, err := Get[int8](config, "redis.addr"); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("err = %v", err)
+	}
+	if d := GetOr(config, "gin.timeout", time.Second); d != 30*time.Second {
+		t.Fatalf("GetOr = %v", d)
+	}
+	if d := GetOr(config, "gin.idle", time.Second); d != time.Second {
+		t.Fatalf("GetOr = %v", d)
+	}
+	if routes := MustGet[[]*net.IPNet](config, "routes"); len(routes) != 1 {
+		t.Fatalf("MustGet = %v", routes)
+	}
+	if v := MustGet[*AnyValue](config, "redis"); v.Get("db").AsInt(-1) != 0 {
+		t.Fatal("MustGet[*AnyValue]")
+	}
+
+	type redisConf struct {
+		Addr    string `json:"addr"`
+		MaxConn int    `json:"max_conn"`
+	}
+	if r, err := Get[redisConf](config, "redis"); err != nil || r.MaxConn != 100 {
+		t.Fatalf("Get[struct] = %+v, %v", r, err)
+	}
+
+	func() {
+		defer func() {
+			if err, ok := recover().(error); !ok || !errors.Is(err, ErrNotFound) {
+				t.Fatalf("recover() = %v", err)
+			}
+		}()
+		MustGet[string](config, "redis.user")
+	}()
+
+	if config.Get("redis.addr").AsInt(1, 2) != 1 {
+		t.Fatal("extra defaults")
+	}
+	if config.Get("redis.max_conn").WithCoercion(Lenient).AsStr("none") != "none" {
+		t.Fatal("AsStr converted without WithConversion")
+	}
+}
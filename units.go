@@ -0,0 +1,144 @@
+package anyvalue
+
+import (
+	"math/big"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// TimeLayouts are the layouts Time tries when it is given none: RFC 3339
+// and the timestamp forms of YAML, which the YAML decoder leaves as strings
+var TimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-1-2T15:4:5.999999999Z07:00",
+	"2006-1-2t15:4:5.999999999Z07:00",
+	"2006-1-2 15:4:5.999999999",
+	"2006-1-2",
+}
+
+// byteUnits are the multipliers of ByteSize, SI units are powers of 1000
+// and IEC units powers of 1024
+var byteUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"pb":  1e15,
+	"eb":  1e18,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+	"pib": 1 << 50,
+	"eib": 1 << 60,
+}
+
+// maxSizeScale bounds the exponent of byte sizes, larger ones overflow an
+// uint64 or are not whole bytes
+const maxSizeScale = 40
+
+var (
+	nanosPerSecond = big.NewRat(int64(time.Second), 1)
+	maxInt64       = new(big.Rat).SetInt64(1<<63 - 1)
+	minInt64       = new(big.Rat).SetInt64(-1 << 63)
+)
+
+// Duration reads a time.Duration from a string such as "30s" or "1h30m",
+// as time.ParseDuration does, or from a number of seconds
+func (j *AnyValue) Duration() (time.Duration, error) {
+	if d, ok := j.data.(string); ok {
+		parsed, err := time.ParseDuration(d)
+		if err != nil {
+			return 0, j.invalid(String, "invalid duration %q", d)
+		}
+		return parsed, nil
+	}
+	r, err := j.number()
+	if err != nil {
+		return 0, err
+	}
+	ns, err := j.nanos(r, "time.Duration")
+	return time.Duration(ns), err
+}
+
+// nanos converts a number of seconds into nanoseconds
+func (j *AnyValue) nanos(secs *big.Rat, typ string) (int64, error) {
+	ns := new(big.Rat).Mul(secs, nanosPerSecond)
+	if ns.Cmp(minInt64) < 0 || ns.Cmp(maxInt64) > 0 {
+		return 0, j.numberError(secs, typ, "overflows", ErrOverflow)
+	}
+	if !ns.IsInt() && j.options().coercion == Strict {
+		return 0, j.numberError(secs, typ, "has a fraction of a nanosecond, cannot convert to", ErrTypeMismatch)
+	}
+	return new(big.Int).Quo(ns.Num(), ns.Denom()).Int64(), nil
+}
+
+// Time reads a time.Time from a string in one of `layouts`, TimeLayouts
+// when none is given, or from a number of seconds since the Unix epoch.
+// Times without a zone are UTC, as are those read from a number.
+func (j *AnyValue) Time(layouts ...string) (time.Time, error) {
+	switch t := j.data.(type) {
+	case time.Time:
+		return t, nil
+	case string:
+		if len(layouts) == 0 {
+			layouts = TimeLayouts
+		}
+		for _, layout := range layouts {
+			if parsed, err := time.Parse(layout, t); err == nil {
+				return parsed, nil
+			}
+		}
+		return time.Time{}, j.invalid(Time, "invalid time %q", t)
+	}
+	r, err := j.number()
+	if err != nil {
+		return time.Time{}, err
+	}
+	ns, err := j.nanos(r, "time.Time")
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, ns).UTC(), nil
+}
+
+// ByteSize reads a number of bytes from a number or a string such as
+// "512", "10MB" or "1.5 GiB". SI units (kB, MB, GB, TB, PB, EB) are powers
+// of 1000 and IEC units (KiB, MiB, GiB, TiB, PiB, EiB) powers of 1024,
+// units are not case sensitive.
+func (j *AnyValue) ByteSize() (uint64, error) {
+	s, ok := j.data.(string)
+	if !ok {
+		return j.unsigned(64, "byte size")
+	}
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, unicode.IsLetter)
+	if i < 0 {
+		i = len(s)
+	}
+	unit, ok := byteUnits[strings.ToLower(s[i:])]
+	d, err := ParseDecimal(strings.TrimSpace(s[:i]))
+	if !ok || err != nil || d.scale < -maxSizeScale || d.scale > maxSizeScale {
+		return 0, j.invalid(Number, "invalid byte size %q", s)
+	}
+	return j.unsignedRat(new(big.Rat).Mul(d.Rat(), big.NewRat(unit, 1)), 64, "byte size")
+}
+
+// AsDuration guarantees the return of a `time.Duration` (with optional default)
+func (j *AnyValue) AsDuration(args ...time.Duration) time.Duration {
+	return orDefault(j.Duration, args)
+}
+
+// AsTime guarantees the return of a `time.Time` (with optional default),
+// strings are parsed with TimeLayouts
+func (j *AnyValue) AsTime(args ...time.Time) time.Time {
+	return orDefault(func() (time.Time, error) { return j.Time() }, args)
+}
+
+// AsByteSize guarantees the return of a byte size (with optional default)
+func (j *AnyValue) AsByteSize(args ...uint64) uint64 {
+	return orDefault(j.ByteSize, args)
+}
@@ -0,0 +1,146 @@
+package anyvalue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDuration(t *testing.T) {
+	doc, err := NewFromYaml([]byte("timeout: 30s\nidle: 90\nretry: 1.5\nbad: soon\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d, err := doc.Get("timeout").Duration(); err != nil || d != 30*time.Second {
+		t.Fatalf("Duration() = %v, %v", d, err)
+	}
+	if d, err := doc.Get("idle").Duration(); err != nil || d != 90*time.Second {
+		t.Fatalf("Duration() = %v, %v", d, err)
+	}
+	if d := doc.Get("retry").AsDuration(); d != 1500*time.Millisecond {
+		t.Fatalf("AsDuration() = %v", d)
+	}
+	if _, err := doc.Get("bad").Duration(); err == nil || err.Error() != `bad: invalid duration "soon"` {
+		t.Fatalf("err = %v", err)
+	}
+	if doc.Get("missing").AsDuration(time.Minute) != time.Minute {
+		t.Fatal("default ignored")
+	}
+
+	doc.Set("keepalive", 90*time.Second)
+	yml, err := doc.EncodeYaml()
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := NewFromYaml(yml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, err := back.Get("keepalive").Duration(); err != nil || d != 90*time.Second {
+		t.Fatalf("Duration() = %v, %v in %s", d, err, yml)
+	}
+}
+
+func TestTime(t *testing.T) {
+	doc, err := NewFromYaml([]byte("expires: 2026-01-01T00:00:00Z\nday: 2026-03-04\nepoch: 1700000000\nlocal: 03/04/2026\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if tm, err := doc.Get("expires").Time(); err != nil || !tm.Equal(want) {
+		t.Fatalf("Time() = %v, %v", tm, err)
+	}
+	if tm := doc.Get("day").AsTime(); !tm.Equal(time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("AsTime() = %v", tm)
+	}
+	if tm, err := doc.Get("epoch").Time(); err != nil || tm.Unix() != 1700000000 {
+		t.Fatalf("Time() = %v, %v", tm, err)
+	}
+	if _, err := doc.Get("local").Time(); err == nil {
+		t.Fatal("expected layout error")
+	}
+	if tm, err := doc.Get("local").Time("01/02/2006"); err != nil || tm.Month() != time.March {
+		t.Fatalf("Time() = %v, %v", tm, err)
+	}
+
+	doc.Set("created", want)
+	for _, encode := range []func() ([]byte, error){doc.EncodeJson, doc.EncodeYaml, doc.EncodeMsgPack} {
+		if _, err := encode(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mp, _ := doc.EncodeMsgPack()
+	back, err := NewFromMsgPack(mp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tm, err := back.Get("created").Time(); err != nil || !tm.Equal(want) {
+		t.Fatalf("Time() = %v, %v", tm, err)
+	}
+
+	var out struct {
+		Expires time.Time     `json:"expires"`
+		Day     time.Time     `json:"day"`
+		Timeout time.Duration `json:"timeout"`
+	}
+	doc.Set("timeout", "1m")
+	if err := doc.Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !out.Expires.Equal(want) || out.Day.Day() != 4 || out.Timeout != time.Minute {
+		t.Fatalf("out = %+v", out)
+	}
+}
+
+func TestByteSize(t *testing.T) {
+	doc, err := NewFromYaml([]byte("body: 10MB\nbuf: 1.5 KiB\nraw: 512\nhuge: 20EB\nodd: 1.5B\nbad: 10 parsecs\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]uint64{"body": 10e6, "buf": 1536, "raw": 512} {
+		if n, err := doc.Get(path).ByteSize(); err != nil || n != want {
+			t.Fatalf("%s: ByteSize() = %v, %v", path, n, err)
+		}
+	}
+	for _, path := range []string{"huge", "odd", "bad"} {
+		if _, err := doc.Get(path).ByteSize(); err == nil {
+			t.Fatalf("%s: expected error", path)
+		}
+	}
+	if doc.Get("bad").AsByteSize(64) != 64 {
+		t.Fatal("default ignored")
+	}
+	if n := doc.WithCoercion(Lenient).Get("odd").AsByteSize(); n != 1 {
+		t.Fatalf("AsByteSize() = %v", n)
+	}
+}
+
+func TestDurationStoredAsString(t *testing.T) {
+	doc := New().Set("timeout", 30*time.Second)
+	if doc.Get("timeout").Kind() != String || doc.Get("timeout").AsStr() != "30s" {
+		t.Fatalf("timeout = %#v", doc.Get("timeout").Interface())
+	}
+	fromStruct, err := FromValue(struct {
+		Timeout time.Duration `json:"timeout"`
+	}{30 * time.Second})
+	if err != nil || !fromStruct.Equal(doc) {
+		t.Fatalf("FromValue = %v, %v", fromStruct, err)
+	}
+	if !doc.Clone().Equal(doc) || len(Diff(doc, doc.Clone()).AsArray()) != 0 {
+		t.Fatal("a clone differs from its original")
+	}
+
+	js, _ := doc.EncodeJson()
+	yml, _ := doc.EncodeYaml()
+	mp, _ := doc.EncodeMsgPack()
+	fromJSON, _ := NewFromJson(js)
+	fromYAML, _ := NewFromYaml(yml)
+	fromMsgPack, _ := NewFromMsgPack(mp)
+	for _, back := range []*AnyValue{fromJSON, fromYAML, fromMsgPack} {
+		if !back.Equal(doc) || back.Get("timeout").AsDuration() != 30*time.Second {
+			t.Fatalf("round trip = %v", back)
+		}
+	}
+}
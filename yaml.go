@@ -0,0 +1,95 @@
+package anyvalue
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// decodeYamlData decodes the first document of `r` like yaml.v2 does into an
+// interface{}, except that !!binary values become a []byte. yaml.v2 decodes
+// them to strings and only reveals the tag of a node through the errors of
+// a failed unmarshal, so documents without the tag take the plain path.
+func decodeYamlData(r io.Reader) (interface{}, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(body))
+	if !bytes.Contains(body, []byte("!!binary")) && !bytes.Contains(body, []byte("tag:yaml.org,2002:binary")) {
+		var data interface{}
+		err := dec.Decode(&data)
+		return data, err
+	}
+	var v yamlValue
+	err = dec.Decode(&v)
+	return v.data, err
+}
+
+// yamlValue decodes a node of any kind, keeping !!binary scalars as []byte
+type yamlValue struct {
+	data interface{}
+}
+
+// yamlProbe has no fields: a mapping decodes into it, while a sequence or a
+// scalar fails with an error naming its tag
+type yamlProbe struct{}
+
+func (v *yamlValue) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	switch yamlTag(unmarshal(&yamlProbe{})) {
+	case "":
+		var m map[interface{}]yamlValue
+		if err := unmarshal(&m); err != nil {
+			return err
+		}
+		data := make(map[interface{}]interface{}, len(m))
+		for k, e := range m {
+			data[k] = e.data
+		}
+		v.data = data
+	case "!!seq":
+		var a []yamlValue
+		if err := unmarshal(&a); err != nil {
+			return err
+		}
+		data := make([]interface{}, len(a))
+		for i, e := range a {
+			data[i] = e.data
+		}
+		v.data = data
+	case "!!binary":
+		var s string
+		if err := unmarshal(&s); err != nil {
+			return err
+		}
+		v.data = []byte(s)
+	default:
+		return unmarshal(&v.data)
+	}
+	return nil
+}
+
+// yamlTag returns the tag named by the error of unmarshaling a node into a
+// yamlProbe, such as "!!seq" or "!!str", or "" when there was no error
+func yamlTag(err error) string {
+	te, ok := err.(*yaml.TypeError)
+	if !ok || len(te.Errors) == 0 {
+		if err != nil {
+			return "?"
+		}
+		return ""
+	}
+	const prefix = "cannot unmarshal "
+	msg := te.Errors[0]
+	i := strings.Index(msg, prefix)
+	if i < 0 {
+		return "?"
+	}
+	msg = msg[i+len(prefix):]
+	if j := strings.IndexByte(msg, ' '); j >= 0 {
+		msg = msg[:j]
+	}
+	return msg
+}
@@ -0,0 +1,63 @@
+package anyvalue
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestYamlBinary(t *testing.T) {
+	plain := `
+base: &base {host: a, port: 80}
+server:
+  <<: *base
+  port: 81
+list: [1, 2.5, "3", null, true, 2024-01-02]
+empty: {}
+none: []
+`
+	want, err := NewFromYaml([]byte(plain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := NewFromYaml([]byte(plain + "keys: [!!binary a2V5, {k: !!binary AP8=}]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b, err := got.Get("keys[0]").Bytes(); err != nil || string(b) != "key" || got.Get("keys[0]").Kind() != Binary {
+		t.Fatalf("keys[0] = %q, %v", b, err)
+	}
+	if b, err := got.Get("keys[1].k").Bytes(); err != nil || len(b) != 2 || b[1] != 0xff {
+		t.Fatalf("keys[1].k = %v, %v", b, err)
+	}
+	got.Del("keys")
+	if !got.Equal(want) {
+		t.Fatalf("decoded %v, want %v", got, want)
+	}
+
+	if _, err := NewFromYaml([]byte("a: [!!binary '*']\n")); err == nil {
+		t.Fatal("expected an error for invalid base64 data")
+	}
+}
+
+// TestYamlTagFormat pins the yaml.v2 error text that yamlTag parses, a new
+// wording would otherwise make !!binary values silently decode as strings
+func TestYamlTagFormat(t *testing.T) {
+	cases := map[string]string{
+		"a: 1":                 "",
+		"{}":                   "",
+		"[1, 2]":               "!!seq",
+		"!!binary aGVsbG8=":    "!!binary",
+		"hello":                "!!str",
+		"12":                   "!!int",
+		"~":                    "",
+		"!!binary |\n  aGk=\n": "!!binary",
+	}
+	for doc, want := range cases {
+		err := yaml.Unmarshal([]byte(doc), &yamlProbe{})
+		if got := yamlTag(err); got != want {
+			t.Fatalf("yamlTag(%q) = %q, want %q (err %v)", doc, got, want, err)
+		}
+	}
+}